@@ -2,13 +2,20 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	stdlog "log"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/ayehia0/deathmatch/internal/config"
+	"github.com/ayehia0/deathmatch/internal/game"
 	sshhandler "github.com/ayehia0/deathmatch/internal/ssh"
+	"github.com/ayehia0/deathmatch/internal/ui"
+	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/bubbletea"
@@ -16,41 +23,85 @@ import (
 	"github.com/muesli/termenv"
 )
 
-const (
-	host = "0.0.0.0"
-	port = "2222"
-)
-
 func main() {
-	s, err := wish.NewServer(
-		wish.WithAddress(host+":"+port),
-		wish.WithHostKeyPath(".ssh/id_ed25519"),
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	level, _ := log.ParseLevel(cfg.LogLevel)
+	log.SetLevel(level)
+	// Route the standard logger used by the game packages through the
+	// leveled logger so they respect the configured level.
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(log.StandardLog(log.StandardLogOptions{ForceLevel: log.WarnLevel}).Writer())
+
+	var scores game.ScoreStore
+	var accounts game.AccountStore
+	var saves game.SaveStore
+	if cfg.ScoresBackend == "memory" {
+		scores = game.NewMemoryScoreStore()
+		accounts = game.NewMemoryAccountStore()
+		saves = game.NewMemorySaveStore()
+		log.Info("Keeping scores, accounts and saved games in memory")
+	} else {
+		scores = game.NewFileScoreStore(cfg.ScoresPath)
+		accounts = game.NewFileAccountStore(cfg.AccountsPath)
+		saves = game.NewFileSaveStore(cfg.SavesDir)
+		log.Info("Saving scores", "path", cfg.ScoresPath, "accounts", cfg.AccountsPath, "saves", cfg.SavesDir)
+	}
+
+	opts := ui.Options{
+		Scores:         scores,
+		ReplaysDir:     cfg.ReplaysDir,
+		MaxReplays:     cfg.MaxReplays,
+		DefaultSkill:   cfg.Skill(),
+		DiagonalSkills: cfg.DiagonalSkillLevels(),
+		UndoBudget:     cfg.UndoBudget,
+		Saves:          saves,
+	}
+
+	serverOpts := []ssh.Option{
+		wish.WithAddress(cfg.Listen),
+		wish.WithHostKeyPath(cfg.HostKeyPath),
 		wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
 			return true // Allow all connections
 		}),
 		wish.WithMiddleware(
-			bubbletea.MiddlewareWithColorProfile(sshhandler.TeaHandler(), termenv.TrueColor),
+			sshhandler.SaveOnExit(),
+			bubbletea.MiddlewareWithColorProfile(sshhandler.TeaHandler(opts, accounts), termenv.TrueColor),
+			sshhandler.CommandMiddleware(accounts),
+			sshhandler.LimitSessions(cfg.MaxSessions),
 			logging.Middleware(),
 		),
-	)
+	}
+	if cfg.IdleTimeout > 0 {
+		serverOpts = append(serverOpts, wish.WithIdleTimeout(cfg.IdleTimeout))
+	}
+
+	s, err := wish.NewServer(serverOpts...)
 	if err != nil {
-		log.Fatalln(err)
+		log.Fatal("Could not create server", "error", err)
 	}
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	log.Printf("Starting SSH server on %s:%s", host, port)
+	log.Info("Starting SSH server", "address", cfg.Listen)
 	go func() {
-		if err = s.ListenAndServe(); err != nil {
-			log.Fatalln(err)
+		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			log.Fatal("Could not serve", "error", err)
 		}
 	}()
 
 	<-done
-	log.Println("Stopping SSH server")
+	log.Info("Stopping SSH server")
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	if err := s.Shutdown(ctx); err != nil {
-		log.Fatalln(err)
+		log.Fatal("Could not stop server", "error", err)
 	}
 }
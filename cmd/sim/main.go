@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ayehia0/deathmatch/internal/game"
+	"github.com/ayehia0/deathmatch/internal/sim"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "number of games to simulate per strategy")
+	strategy := flag.String("strategy", "evasive", "bot strategy, or \"all\": "+strings.Join(sim.StrategyNames(), ", "))
+	skillName := flag.String("skill", game.SkillStandard.String(), "skill level to play on")
+	seed := flag.Uint64("seed", 1, "seed of the first game; game i uses seed+i")
+	width := flag.Int("width", 38, "arena width in cells")
+	height := flag.Int("height", 19, "arena height in cells")
+	campaign := flag.Bool("campaign", false, "play the bundled campaign levels before random ones")
+	maxTurns := flag.Int("max-turns", 5000, "stop a game that survives this many turns")
+	format := flag.String("format", "csv", "output format: csv or json")
+	workers := flag.Int("workers", 0, "parallel games, 0 for one per CPU")
+	flag.Parse()
+
+	skill, ok := game.ParseSkill(*skillName)
+	if !ok {
+		fail("unknown skill %q", *skillName)
+	}
+	if *format != "csv" && *format != "json" {
+		fail("unknown format %q", *format)
+	}
+	if *games < 0 {
+		fail("-games %d must not be negative", *games)
+	}
+	if *width < 1 || *height < 1 {
+		fail("arena %dx%d must be at least 1x1", *width, *height)
+	}
+	if *maxTurns < 1 {
+		fail("-max-turns %d must be positive", *maxTurns)
+	}
+
+	names := []string{*strategy}
+	if *strategy == "all" {
+		names = sim.StrategyNames()
+	}
+
+	difficulty := skill.Difficulty()
+	difficulty.Campaign = *campaign
+
+	cfg := sim.Config{
+		Width:      *width,
+		Height:     *height,
+		Difficulty: difficulty,
+		MaxTurns:   *maxTurns,
+		Workers:    *workers,
+	}
+
+	seeds := make([]uint64, *games)
+	for i := range seeds {
+		seeds[i] = *seed + uint64(i)
+	}
+
+	var results []sim.Result
+	for _, name := range names {
+		s, ok := sim.Strategies[name]
+		if !ok {
+			fail("unknown strategy %q", name)
+		}
+		batch := sim.RunMany(cfg, name, s, seeds)
+		summarize(os.Stderr, name, batch)
+		results = append(results, batch...)
+	}
+
+	var err error
+	if *format == "json" {
+		err = writeJSON(os.Stdout, results)
+	} else {
+		err = writeCSV(os.Stdout, results)
+	}
+	if err != nil {
+		fail("writing results: %v", err)
+	}
+}
+
+func writeCSV(w io.Writer, results []sim.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"seed", "strategy", "skill", "level", "score", "turns", "cause"})
+	for _, r := range results {
+		cw.Write([]string{
+			strconv.FormatUint(r.Seed, 10),
+			r.Strategy,
+			r.Skill,
+			strconv.Itoa(r.Level),
+			strconv.Itoa(r.Score),
+			strconv.Itoa(r.Turns),
+			r.Cause,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, results []sim.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func summarize(w io.Writer, name string, results []sim.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	var levels, scores, turns int
+	causes := map[string]int{}
+	for _, r := range results {
+		levels += r.Level
+		scores += r.Score
+		turns += r.Turns
+		causes[r.Cause]++
+	}
+
+	n := float64(len(results))
+	fmt.Fprintf(w, "%s: %d games, avg level %.2f, avg score %.1f, avg turns %.1f, causes %v\n",
+		name, len(results), float64(levels)/n, float64(scores)/n, float64(turns)/n, causes)
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "sim: "+format+"\n", args...)
+	os.Exit(2)
+}
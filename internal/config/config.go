@@ -0,0 +1,307 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ayehia0/deathmatch/internal/game"
+)
+
+const envPrefix = "DEATHMATCH_"
+
+type Config struct {
+	Listen        string `toml:"listen"`
+	HostKeyPath   string `toml:"host_key_path"`
+	ScoresBackend string `toml:"scores_backend"`
+	ScoresPath    string `toml:"scores_path"`
+	AccountsPath  string `toml:"accounts_path"`
+	ReplaysDir    string `toml:"replays_dir"`
+	SavesDir      string `toml:"saves_dir"`
+	DefaultSkill  string `toml:"default_skill"`
+	// DiagonalSkills overrides which skill levels allow diagonal moves;
+	// unset keeps the presets.
+	DiagonalSkills []string `toml:"diagonal_skills"`
+	// UndoBudget overrides how many turns each level may be taken back;
+	// unset keeps the presets.
+	UndoBudget  *int          `toml:"undo_budget"`
+	MaxReplays  int           `toml:"max_replays"`
+	MaxSessions int           `toml:"max_sessions"`
+	IdleTimeout time.Duration `toml:"idle_timeout"`
+	LogLevel    string        `toml:"log_level"`
+}
+
+func Default() Config {
+	return Config{
+		Listen:        "0.0.0.0:2222",
+		HostKeyPath:   ".ssh/id_ed25519",
+		ScoresBackend: "file",
+		ScoresPath:    dataPath("scores.txt"),
+		AccountsPath:  dataPath("accounts.txt"),
+		ReplaysDir:    dataPath("replays"),
+		MaxReplays:    1000,
+		SavesDir:      dataPath("saves"),
+		DefaultSkill:  game.SkillStandard.String(),
+		MaxSessions:   0,
+		IdleTimeout:   10 * time.Minute,
+		LogLevel:      "info",
+	}
+}
+
+// dataPath keeps server data in the user's config directory so it survives
+// the server being started from different working directories.
+func dataPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(dir, "deathmatch", name)
+}
+
+type setting struct {
+	name  string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+func (s setting) env() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+var settings = []setting{
+	{"listen", "address to listen on (host:port)", func(c *Config, v string) error {
+		c.Listen = v
+		return nil
+	}},
+	{"host-key-path", "path to the SSH host key, generated if missing", func(c *Config, v string) error {
+		c.HostKeyPath = v
+		return nil
+	}},
+	{"scores-backend", "where scores, accounts and unfinished games are kept: file or memory", func(c *Config, v string) error {
+		c.ScoresBackend = v
+		return nil
+	}},
+	{"scores-path", "leaderboard file for the file backend", func(c *Config, v string) error {
+		c.ScoresPath = v
+		return nil
+	}},
+	{"accounts-path", "player key registry for the file backend", func(c *Config, v string) error {
+		c.AccountsPath = v
+		return nil
+	}},
+	{"replays-dir", "directory finished games' replays are written to", func(c *Config, v string) error {
+		c.ReplaysDir = v
+		return nil
+	}},
+	{"max-replays", "replays to keep, oldest deleted first, 0 to keep all", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("max-replays: %q is not a number", v)
+		}
+		c.MaxReplays = n
+		return nil
+	}},
+	{"saves-dir", "directory unfinished games are kept in for the file backend", func(c *Config, v string) error {
+		c.SavesDir = v
+		return nil
+	}},
+	{"default-skill", "skill level preselected for new players", func(c *Config, v string) error {
+		c.DefaultSkill = v
+		return nil
+	}},
+	{"diagonal-skills", "comma-separated skill levels that allow diagonal moves, or none", func(c *Config, v string) error {
+		c.DiagonalSkills = []string{}
+		if v == "none" {
+			return nil
+		}
+		for _, name := range strings.Split(v, ",") {
+			c.DiagonalSkills = append(c.DiagonalSkills, strings.TrimSpace(name))
+		}
+		return nil
+	}},
+	{"undo-budget", "turns that may be taken back each level, overriding the skill presets", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("undo-budget: %q is not a number", v)
+		}
+		c.UndoBudget = &n
+		return nil
+	}},
+	{"max-sessions", "maximum concurrent SSH sessions, 0 for unlimited", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("max-sessions: %q is not a number", v)
+		}
+		c.MaxSessions = n
+		return nil
+	}},
+	{"idle-timeout", "disconnect idle sessions after this long, 0 to disable", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("idle-timeout: %q is not a duration", v)
+		}
+		c.IdleTimeout = d
+		return nil
+	}},
+	{"log-level", "debug, info, warn or error", func(c *Config, v string) error {
+		c.LogLevel = v
+		return nil
+	}},
+}
+
+// Load builds the configuration from, in increasing priority: defaults, the
+// TOML file named by -config or DEATHMATCH_CONFIG, DEATHMATCH_* environment
+// variables and command-line flags.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	fs := flag.NewFlagSet("deathmatch", flag.ContinueOnError)
+	configPath := fs.String("config", getenv(envPrefix+"CONFIG"), "path to a TOML config file (env "+envPrefix+"CONFIG)")
+
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		fs.Func(s.name, s.usage+" (env "+s.env()+")", func(v string) error {
+			flagValues[s.name] = v
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+
+	if *configPath != "" {
+		md, err := toml.DecodeFile(*configPath, &cfg)
+		if err != nil {
+			return Config{}, fmt.Errorf("reading config %s: %w", *configPath, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return Config{}, fmt.Errorf("reading config %s: unknown setting %q", *configPath, undecoded[0].String())
+		}
+	}
+
+	for _, s := range settings {
+		if v := getenv(s.env()); v != "" {
+			if err := s.set(&cfg, v); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", s.env(), err)
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := flagValues[s.name]; ok {
+			if err := s.set(&cfg, v); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	var errs []error
+
+	if _, port, err := net.SplitHostPort(c.Listen); err != nil {
+		errs = append(errs, fmt.Errorf("listen: %q must be host:port", c.Listen))
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		errs = append(errs, fmt.Errorf("listen: port %q must be between 1 and 65535", port))
+	}
+
+	if c.HostKeyPath == "" {
+		errs = append(errs, errors.New("host_key_path: must not be empty"))
+	}
+
+	switch c.ScoresBackend {
+	case "file":
+		if c.ScoresPath == "" {
+			errs = append(errs, errors.New("scores_path: must not be empty with the file backend"))
+		}
+		if c.AccountsPath == "" {
+			errs = append(errs, errors.New("accounts_path: must not be empty with the file backend"))
+		}
+		if c.SavesDir == "" {
+			errs = append(errs, errors.New("saves_dir: must not be empty with the file backend"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("scores_backend: %q must be file or memory", c.ScoresBackend))
+	}
+
+	if c.ReplaysDir == "" {
+		errs = append(errs, errors.New("replays_dir: must not be empty"))
+	}
+
+	names := make([]string, len(game.Skills))
+	for i, s := range game.Skills {
+		names[i] = s.String()
+	}
+	if _, ok := game.ParseSkill(c.DefaultSkill); !ok {
+		errs = append(errs, fmt.Errorf("default_skill: %q must be one of %s", c.DefaultSkill, strings.Join(names, ", ")))
+	}
+	for _, name := range c.DiagonalSkills {
+		if _, ok := game.ParseSkill(name); !ok {
+			errs = append(errs, fmt.Errorf("diagonal_skills: %q must be one of %s", name, strings.Join(names, ", ")))
+		}
+	}
+
+	if c.UndoBudget != nil && *c.UndoBudget < 0 {
+		errs = append(errs, fmt.Errorf("undo_budget: %d must not be negative", *c.UndoBudget))
+	}
+
+	if c.MaxReplays < 0 {
+		errs = append(errs, fmt.Errorf("max_replays: %d must not be negative", c.MaxReplays))
+	}
+
+	if c.MaxSessions < 0 {
+		errs = append(errs, fmt.Errorf("max_sessions: %d must not be negative", c.MaxSessions))
+	}
+
+	if c.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("idle_timeout: %s must not be negative", c.IdleTimeout))
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log_level: %q must be debug, info, warn or error", c.LogLevel))
+	}
+
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		return errors.New("invalid config:\n  " + strings.Join(msgs, "\n  "))
+	}
+	return nil
+}
+
+func (c Config) Skill() game.Skill {
+	skill, _ := game.ParseSkill(c.DefaultSkill)
+	return skill
+}
+
+// DiagonalSkillLevels returns the skill levels that allow diagonal moves, or
+// nil to keep the presets.
+func (c Config) DiagonalSkillLevels() []game.Skill {
+	if c.DiagonalSkills == nil {
+		return nil
+	}
+	skills := []game.Skill{}
+	for _, name := range c.DiagonalSkills {
+		skill, _ := game.ParseSkill(name)
+		skills = append(skills, skill)
+	}
+	return skills
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNoKey    = errors.New("a public key is required to claim a name")
+	ErrNotOwner = errors.New("only a key already linked to this account can link new keys")
+	ErrBadField = errors.New("names and fingerprints must not contain newlines, '|' or ','")
+)
+
+// validField reports whether s can be written to the accounts file without
+// breaking its line and field separators.
+func validField(s string) bool {
+	return !strings.ContainsAny(s, "\n\r|,")
+}
+
+// AccountStore binds player names to SSH public key fingerprints. The first
+// key to claim a name owns it; other keys asking for it get a suffixed name.
+type AccountStore interface {
+	// Claim returns the name the key with fingerprint plays under.
+	Claim(name, fingerprint string) (string, error)
+	// Link adds fingerprint to name's account, authorized by the owner key.
+	Link(name, owner, fingerprint string) error
+}
+
+// accounts maps an account name to the fingerprints linked to it.
+type accounts map[string][]string
+
+func (a accounts) owns(name, fingerprint string) bool {
+	return slices.Contains(a[name], fingerprint)
+}
+
+func (a accounts) claim(name, fingerprint string) (string, bool, error) {
+	if fingerprint == "" {
+		return "", false, ErrNoKey
+	}
+	if !validField(name) || !validField(fingerprint) {
+		return "", false, ErrBadField
+	}
+
+	candidate := name
+	for n := 2; ; n++ {
+		if a.owns(candidate, fingerprint) {
+			return candidate, false, nil
+		}
+		if len(a[candidate]) == 0 {
+			a[candidate] = []string{fingerprint}
+			return candidate, true, nil
+		}
+		candidate = name + "-" + strconv.Itoa(n)
+	}
+}
+
+func (a accounts) link(name, owner, fingerprint string) (bool, error) {
+	if owner == "" || fingerprint == "" {
+		return false, ErrNoKey
+	}
+	if !validField(name) || !validField(owner) || !validField(fingerprint) {
+		return false, ErrBadField
+	}
+	if !a.owns(name, owner) {
+		return false, ErrNotOwner
+	}
+	if a.owns(name, fingerprint) {
+		return false, nil
+	}
+	a[name] = append(a[name], fingerprint)
+	return true, nil
+}
+
+type FileAccountStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileAccountStore(path string) *FileAccountStore {
+	return &FileAccountStore{path: path}
+}
+
+func (s *FileAccountStore) Claim(name, fingerprint string) (string, error) {
+	var claimed string
+	err := s.update(func(a accounts) (bool, error) {
+		var changed bool
+		var err error
+		claimed, changed, err = a.claim(name, fingerprint)
+		return changed, err
+	})
+	return claimed, err
+}
+
+func (s *FileAccountStore) Link(name, owner, fingerprint string) error {
+	return s.update(func(a accounts) (bool, error) {
+		return a.link(name, owner, fingerprint)
+	})
+}
+
+// update loads the accounts under the store's locks, applies fn and writes
+// the result back if fn changed anything.
+func (s *FileAccountStore) update(fn func(a accounts) (bool, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withFileLock(s.path, func() error {
+		a, err := s.load()
+		if err != nil {
+			return err
+		}
+
+		changed, err := fn(a)
+		if err != nil || !changed {
+			return err
+		}
+
+		names := make([]string, 0, len(a))
+		for name := range a {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return writeFileAtomic(s.path, func(w io.Writer) error {
+			for _, name := range names {
+				if _, err := io.WriteString(w, name+"|"+strings.Join(a[name], ",")+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (s *FileAccountStore) load() (accounts, error) {
+	a := accounts{}
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, keys, ok := strings.Cut(scanner.Text(), "|")
+		if ok && keys != "" {
+			a[name] = strings.Split(keys, ",")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+type MemoryAccountStore struct {
+	mu       sync.Mutex
+	accounts accounts
+}
+
+func NewMemoryAccountStore() *MemoryAccountStore {
+	return &MemoryAccountStore{accounts: accounts{}}
+}
+
+func (s *MemoryAccountStore) Claim(name, fingerprint string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed, _, err := s.accounts.claim(name, fingerprint)
+	return claimed, err
+}
+
+func (s *MemoryAccountStore) Link(name, owner, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.accounts.link(name, owner, fingerprint)
+	return err
+}
@@ -0,0 +1,36 @@
+package game
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileAccountStoreRejectsInjectedLines(t *testing.T) {
+	s := NewFileAccountStore(filepath.Join(t.TempDir(), "accounts.txt"))
+
+	if _, err := s.Claim("aaron", "SHA256:owner"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Claim("zed", "SHA256:attacker"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fp := range []string{"x\naaron|SHA256:attacker", "x|y", "x,SHA256:attacker"} {
+		if err := s.Link("zed", "SHA256:attacker", fp); !errors.Is(err, ErrBadField) {
+			t.Errorf("Link(%q) = %v, want ErrBadField", fp, err)
+		}
+	}
+	if _, err := s.Claim("mallory\naaron", "SHA256:attacker"); !errors.Is(err, ErrBadField) {
+		t.Errorf("Claim with a newline = %v, want ErrBadField", err)
+	}
+
+	name, err := s.Claim("aaron", "SHA256:owner")
+	if err != nil || name != "aaron" {
+		t.Fatalf("owner claims %q, %v; want aaron", name, err)
+	}
+	name, err = s.Claim("aaron", "SHA256:attacker")
+	if err != nil || name != "aaron-2" {
+		t.Fatalf("attacker claims %q, %v; want aaron-2", name, err)
+	}
+}
@@ -0,0 +1,213 @@
+package game
+
+// Event is something that happened inside the engine during a turn. Subscribe
+// to receive them as they happen instead of diffing Game fields.
+type Event interface {
+	event()
+}
+
+// RobotsCollided reports Count robots wrecked against each other at Pos.
+// Two robots that tried to swap places are one collision at the first of
+// their cells, each leaving junk where it stood.
+type RobotsCollided struct {
+	Pos   Position
+	Count int
+}
+
+type RobotHitJunk struct {
+	Pos Position
+}
+
+type RobotHitShrub struct {
+	Pos      Position
+	Exploded bool
+}
+
+type RobotsBlasted struct {
+	Target Position
+	Count  int
+}
+
+type PlayerHurt struct {
+	Health int
+}
+
+type PlayerKilled struct {
+	Cause DeathCause
+}
+
+type LevelCleared struct {
+	Level int
+}
+
+type ToolUsed struct {
+	Tool Tool
+}
+
+type PickupCollected struct {
+	Pos  Position
+	Kind PickupKind
+}
+
+type PickupTrampled struct {
+	Pos  Position
+	Kind PickupKind
+}
+
+// ShieldHit is the player's shield wrecking the robots that reached them.
+type ShieldHit struct {
+	Count int
+}
+
+type ScoreChanged struct {
+	Delta  int
+	Reason ScoreReason
+}
+
+func (RobotsCollided) event()  {}
+func (RobotHitJunk) event()    {}
+func (RobotHitShrub) event()   {}
+func (RobotsBlasted) event()   {}
+func (PlayerHurt) event()      {}
+func (PlayerKilled) event()    {}
+func (LevelCleared) event()    {}
+func (ToolUsed) event()        {}
+func (PickupCollected) event() {}
+func (PickupTrampled) event()  {}
+func (ShieldHit) event()       {}
+func (ScoreChanged) event()    {}
+
+type DeathCause int
+
+const (
+	CauseNone DeathCause = iota
+	CauseRobot
+	CauseJunk
+	CauseObstacle
+	CauseShrubs
+	CauseSelfDestruct
+)
+
+func (c DeathCause) String() string {
+	switch c {
+	case CauseRobot:
+		return "robot"
+	case CauseJunk:
+		return "junk"
+	case CauseObstacle:
+		return "obstacle"
+	case CauseShrubs:
+		return "shrubs"
+	case CauseSelfDestruct:
+		return "self-destruct"
+	default:
+		return "none"
+	}
+}
+
+type Tool int
+
+const (
+	ToolTeleport Tool = iota
+	ToolEMP
+	ToolBlaster
+	ToolSafeTeleport
+	ToolBlink
+)
+
+func (t Tool) String() string {
+	switch t {
+	case ToolTeleport:
+		return "teleport"
+	case ToolEMP:
+		return "emp"
+	case ToolBlaster:
+		return "blaster"
+	case ToolSafeTeleport:
+		return "safe teleport"
+	case ToolBlink:
+		return "blink"
+	default:
+		return "unknown"
+	}
+}
+
+type ScoreReason int
+
+const (
+	ScoreKill ScoreReason = iota
+	ScoreLevelBonus
+	ScoreTeleport
+	ScoreShrub
+	ScoreLastStand
+	ScoreUndo
+)
+
+func (r ScoreReason) String() string {
+	switch r {
+	case ScoreKill:
+		return "kill"
+	case ScoreLevelBonus:
+		return "level bonus"
+	case ScoreTeleport:
+		return "teleport"
+	case ScoreShrub:
+		return "shrub"
+	case ScoreLastStand:
+		return "last stand"
+	case ScoreUndo:
+		return "undo"
+	default:
+		return "unknown"
+	}
+}
+
+func (g *Game) Subscribe(fn func(Event)) {
+	g.subscribers = append(g.subscribers, fn)
+}
+
+func (g *Game) emit(e Event) {
+	g.recordStats(e)
+	for _, fn := range g.subscribers {
+		fn(e)
+	}
+}
+
+func (g *Game) addScore(delta int, reason ScoreReason) {
+	g.Score += delta
+	g.emit(ScoreChanged{Delta: delta, Reason: reason})
+}
+
+// scoreKills awards points for killCount robots worth points between them,
+// growing the consecutive kill multiplier as it goes.
+func (g *Game) scoreKills(killCount, points int) {
+	award := g.killPoints(killCount, points)
+	g.ConsecutiveKills += killCount
+	g.addScore(award, ScoreKill)
+}
+
+// killMultiplier is the multiplier killCount more kills would be scored at,
+// doubled while a multiplier pickup is running.
+func (g *Game) killMultiplier(killCount int) int {
+	multiplier := 1 + (g.ConsecutiveKills+killCount)/5
+	if g.BoostTurns > 0 {
+		multiplier *= 2
+	}
+	return multiplier
+}
+
+func (g *Game) killPoints(killCount, points int) int {
+	return points * g.killMultiplier(killCount)
+}
+
+func (g *Game) kill(cause DeathCause) {
+	g.GameOver = true
+	g.DeathCause = cause
+	g.SelfDestruct = cause == CauseSelfDestruct
+	g.emit(PlayerKilled{Cause: cause})
+}
+
+func (g *Game) clearLevel() {
+	g.emit(LevelCleared{Level: g.Level})
+	g.NextLevel()
+}
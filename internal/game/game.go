@@ -2,6 +2,7 @@ package game
 
 import (
 	"math/rand/v2"
+	"slices"
 )
 
 type Position struct {
@@ -15,128 +16,284 @@ const (
 	EntityObstacle
 	EntityJunk
 	EntityShrub
+	EntityPickup
 )
 
 type Entity struct {
 	Pos  Position
 	Type EntityType
+	// Kind is how a robot hunts; other entities leave it zero.
+	Kind RobotKind
+	// Fast robots take two steps every turn.
+	Fast bool
+	// Stunned is how many more turns an EMP keeps the robot in place.
+	Stunned int
+	// Pickup is what a pickup hands the player, who has TTL more turns to
+	// collect it.
+	Pickup PickupKind
+	TTL    int
 }
 
 type Difficulty struct {
+	Skill         Skill
 	RobotCount    int
 	ObstacleCount int
+	ShrubCount    int
 	MinSpawnDist  int
+
+	// Growth is how many extra entities each level adds. The spawn distance
+	// shrinks by one every two levels until it reaches MinSpawnDistFloor.
+	RobotGrowth       int
+	ObstacleGrowth    int
+	ShrubGrowth       int
+	MinSpawnDistFloor int
+
+	// ShrubExplodeChance is the percentage chance that a robot walking into
+	// a shrub blows up with it instead of crushing it.
+	ShrubExplodeChance int
+
+	// DiagonalMoves lets the player move diagonally like the robots do.
+	DiagonalMoves bool
+
+	// UndoBudget is how many turns the player may take back each level.
+	UndoBudget int
+
+	// Fast robots join from FastRobotLevel on, FastRobotGrowth more each
+	// level. Zero FastRobotLevel means they never appear.
+	FastRobotLevel  int
+	FastRobotGrowth int
+
+	// RobotMix swaps greedy robots for smarter kinds as levels go on.
+	RobotMix []RobotMix
+
+	// Blaster is the weapon the player's blaster charges fire.
+	Blaster Weapon
+
+	// A pickup drops every PickupEvery turns, zero for never, and lasts
+	// PickupTTL turns before it vanishes.
+	PickupEvery int
+	PickupTTL   int
+
+	// Campaign plays the bundled levels in order before going on to random
+	// arenas.
+	Campaign bool
+
+	Teleports      int
+	EMPs           int
+	Blasters       int
+	TeleportRefill int
+	EMPRefill      int
+	BlasterRefill  int
+
+	// Safe teleports never land within a robot's reach and are handed out
+	// more sparingly than plain ones.
+	SafeTeleports      int
+	SafeTeleportRefill int
 }
 
+func (d Difficulty) levelCounts(level int) (robots, fastRobots, obstacles, shrubs, minSpawnDist int) {
+	grown := level - 1
+	robots = d.RobotCount + grown*d.RobotGrowth
+	if d.FastRobotLevel > 0 && level >= d.FastRobotLevel {
+		fastRobots = (level - d.FastRobotLevel + 1) * d.FastRobotGrowth
+	}
+	obstacles = d.ObstacleCount + grown*d.ObstacleGrowth
+	shrubs = d.ShrubCount + grown*d.ShrubGrowth
+	minSpawnDist = max(d.MinSpawnDistFloor, d.MinSpawnDist-grown/2)
+	return robots, fastRobots, obstacles, shrubs, minSpawnDist
+}
+
+const (
+	maxHealth       = 3
+	shrubPenalty    = 5
+	teleportPenalty = 2
+	blinkRange      = 3
+	empTurns        = 5
+	empPulseRadius  = 3
+	empPulseTurns   = 8
+	empWaveRange    = 8
+	empWaveTurns    = 8
+	lastStandBonus  = 10
+	undoPenalty     = 15
+	robotPoints     = 10
+	fastRobotPoints = 25
+)
+
 type Game struct {
 	Width            int
 	Height           int
+	ArenaWidth       int
+	ArenaHeight      int
 	Player           Position
+	LastMove         Position
 	Entities         []Entity
 	GameOver         bool
 	Teleports        int
+	SafeTeleports    int
 	EMPs             int
-	EMPTurnsLeft     int
+	WaveActive       bool
+	WaveDir          Position
 	Score            int
 	Level            int
+	LevelName        string
 	ConsecutiveKills int
 	Blasters         int
+	Weapon           Weapon
 	BlasterActive    bool
 	BlasterTarget    Position
+	BlinkActive      bool
+	BlinkTarget      Position
 	SelfDestruct     bool
+	DeathCause       DeathCause
+	Stats            Stats
+	Health           int
+	Turns            int
+	UndosLeft        int
+	UndosUsed        int
+	ShieldTurns      int
+	BoostTurns       int
+	PickupIn         int
+	Seed             uint64
+	Difficulty       Difficulty
+
+	rng         *rand.Rand
+	src         *rand.PCG
+	actions     []Action
+	subscribers []func(Event)
+	history     []snapshot
+	field       []int
+	occ         *occupancy
 }
 
 func New(width, height int, difficulty Difficulty) *Game {
-	playerPos := Position{X: width / 2, Y: height / 2}
-	occupied := []Position{playerPos}
-
-	entities := []Entity{}
+	return NewWithSeed(width, height, difficulty, rand.Uint64())
+}
 
-	robots := generatePositions(width, height, difficulty.RobotCount, difficulty.MinSpawnDist, occupied)
-	for _, pos := range robots {
-		entities = append(entities, Entity{Pos: pos, Type: EntityRobot})
+// NewWithSeed creates a game whose every random choice is drawn from seed,
+// so the same seed and the same inputs always replay the same run.
+func NewWithSeed(width, height int, difficulty Difficulty, seed uint64) *Game {
+	src := rand.NewPCG(seed, seed)
+	g := &Game{
+		Width:         width,
+		Height:        height,
+		ArenaWidth:    width,
+		ArenaHeight:   height,
+		Teleports:     difficulty.Teleports,
+		SafeTeleports: difficulty.SafeTeleports,
+		EMPs:          difficulty.EMPs,
+		Blasters:      difficulty.Blasters,
+		Weapon:        difficulty.Weapon(),
+		Level:         1,
+		Health:        maxHealth,
+		UndosLeft:     difficulty.UndoBudget,
+		PickupIn:      difficulty.PickupEvery,
+		Seed:          seed,
+		Difficulty:    difficulty,
+		rng:           rand.New(src),
+		src:           src,
 	}
-	occupied = append(occupied, robots...)
 
-	obstacles := generatePositions(width, height, difficulty.ObstacleCount, 0, occupied)
-	for _, pos := range obstacles {
-		entities = append(entities, Entity{Pos: pos, Type: EntityObstacle})
-	}
+	g.setupLevel()
 
-	return &Game{
-		Width:     width,
-		Height:    height,
-		Player:    playerPos,
-		Entities:  entities,
-		Teleports: 5,
-		EMPs:      3,
-		Blasters:  2,
-		Level:     1,
-	}
+	return g
 }
 
 func (g *Game) NextLevel() {
 	g.Level++
-	playerPos := Position{X: g.Width / 2, Y: g.Height / 2}
-	occupied := []Position{playerPos}
+	g.Health = maxHealth
+	g.BlasterActive = false
+	g.BlinkActive = false
+	g.WaveActive = false
+	g.Teleports += g.Difficulty.TeleportRefill
+	g.SafeTeleports += g.Difficulty.SafeTeleportRefill
+	g.EMPs += g.Difficulty.EMPRefill
+	g.Blasters += g.Difficulty.BlasterRefill
+	g.ConsecutiveKills = 0
+	g.UndosLeft = g.Difficulty.UndoBudget
+	g.PickupIn = g.Difficulty.PickupEvery
+	g.history = nil
+	g.setupLevel()
+	g.addScore(50, ScoreLevelBonus)
+}
 
+func (g *Game) spawnEntities(robotCount, fastRobotCount, obstacleCount, shrubCount, minSpawnDist int) []Entity {
+	occupied := []Position{g.Player}
 	entities := []Entity{}
 
-	robotCount := 10 + (g.Level-1)*2
-	obstacleCount := 15 + (g.Level-1)*3
-	minSpawnDist := max(3, 5-(g.Level-1)/2)
-
-	robots := generatePositions(g.Width, g.Height, robotCount, minSpawnDist, occupied)
-	for _, pos := range robots {
-		entities = append(entities, Entity{Pos: pos, Type: EntityRobot})
+	robots := g.generatePositions(robotCount, minSpawnDist, occupied)
+	kinds := g.Difficulty.robotKinds(g.Level, len(robots))
+	for i, pos := range robots {
+		entities = append(entities, Entity{Pos: pos, Type: EntityRobot, Kind: kinds[i]})
 	}
 	occupied = append(occupied, robots...)
 
-	obstacles := generatePositions(g.Width, g.Height, obstacleCount, 0, occupied)
+	// Fast robots close in twice as quickly, so they start twice as far out.
+	fastRobots := g.generatePositions(fastRobotCount, minSpawnDist*2, occupied)
+	for _, pos := range fastRobots {
+		entities = append(entities, Entity{Pos: pos, Type: EntityRobot, Fast: true})
+	}
+	occupied = append(occupied, fastRobots...)
+
+	obstacles := g.generatePositions(obstacleCount, 0, occupied)
 	for _, pos := range obstacles {
 		entities = append(entities, Entity{Pos: pos, Type: EntityObstacle})
 	}
+	occupied = append(occupied, obstacles...)
 
-	g.Player = playerPos
-	g.Entities = entities
-	g.EMPTurnsLeft = 0
-	g.BlasterActive = false
-	g.Teleports += 5
-	g.EMPs += 3
-	g.Blasters += 1
-	g.Score += 50
-	g.ConsecutiveKills = 0
+	shrubs := g.generatePositions(shrubCount, 0, occupied)
+	for _, pos := range shrubs {
+		entities = append(entities, Entity{Pos: pos, Type: EntityShrub})
+	}
+
+	return entities
 }
 
-func generatePositions(width, height, count, minDist int, occupied []Position) []Position {
+func (g *Game) generatePositions(count, minDist int, occupied []Position) []Position {
 	positions := make([]Position, 0, count)
 
-	for len(positions) < count {
-		pos := Position{X: rand.IntN(width), Y: rand.IntN(height)}
+	taken := make([]bool, g.Width*g.Height)
+	for _, p := range occupied {
+		if g.inBounds(p) {
+			taken[p.Y*g.Width+p.X] = true
+		}
+	}
 
-		if isOccupied(pos, occupied) || isOccupied(pos, positions) {
-			continue
+	// Only the player needs room; robots may spawn side by side.
+	allowed := func(pos Position) bool {
+		return !taken[pos.Y*g.Width+pos.X] && (minDist == 0 || isFarEnough(pos, []Position{g.Player}, minDist))
+	}
+
+	// Counting the cells that qualify first means a crowded arena gets as
+	// many as fit instead of searching forever for the rest. Only cells in
+	// the square around the player can be too close to it.
+	free := 0
+	for _, t := range taken {
+		if !t {
+			free++
+		}
+	}
+	for y := g.Player.Y - minDist; y <= g.Player.Y+minDist; y++ {
+		for x := g.Player.X - minDist; x <= g.Player.X+minDist; x++ {
+			if p := (Position{X: x, Y: y}); g.inBounds(p) && !taken[y*g.Width+x] && !allowed(p) {
+				free--
+			}
 		}
+	}
 
-		if minDist > 0 && !isFarEnough(pos, occupied, minDist) {
+	for len(positions) < count && free > 0 {
+		pos := Position{X: g.rng.IntN(g.Width), Y: g.rng.IntN(g.Height)}
+		if !allowed(pos) {
 			continue
 		}
 
 		positions = append(positions, pos)
+		taken[pos.Y*g.Width+pos.X] = true
+		free--
 	}
 
 	return positions
 }
 
-func isOccupied(pos Position, positions []Position) bool {
-	for _, p := range positions {
-		if p.X == pos.X && p.Y == pos.Y {
-			return true
-		}
-	}
-	return false
-}
-
 func isFarEnough(pos Position, positions []Position, minDist int) bool {
 	minDistSq := minDist * minDist
 	for _, p := range positions {
@@ -153,6 +310,10 @@ func (g *Game) MovePlayer(dx, dy int) {
 	if g.GameOver {
 		return
 	}
+	if dx != 0 && dy != 0 && !g.Difficulty.DiagonalMoves {
+		return
+	}
+	g.record(Action{Kind: ActionMove, DX: dx, DY: dy})
 
 	newX := g.Player.X + dx
 	newY := g.Player.Y + dy
@@ -162,158 +323,395 @@ func (g *Game) MovePlayer(dx, dy int) {
 	}
 
 	newPos := Position{X: newX, Y: newY}
-
-	for _, entity := range g.Entities {
-		if entity.Pos.X == newPos.X && entity.Pos.Y == newPos.Y {
-			g.GameOver = true
+	g.checkpoint()
+
+	rammed := false
+	if i := g.firstAt(newPos, nil); i >= 0 {
+		switch g.Entities[i].Type {
+		case EntityPickup:
+			g.collect(i)
+		case EntityShrub:
+			g.hitShrub()
 			return
+		case EntityJunk:
+			g.kill(CauseJunk)
+			return
+		case EntityObstacle:
+			g.kill(CauseObstacle)
+			return
+		default:
+			// A shield wrecks the robot as it would one that reached the
+			// player.
+			if g.ShieldTurns == 0 {
+				g.kill(CauseRobot)
+				return
+			}
+			rammed = true
 		}
 	}
 
 	g.Player = newPos
-	g.MoveRobots()
-	g.CheckCollisions()
+	g.LastMove = Position{X: dx, Y: dy}
+	if rammed {
+		g.shieldHit()
+	}
+	g.passTurn()
 }
 
-func (g *Game) MoveRobots() {
-	if g.EMPTurnsLeft > 0 {
-		g.EMPTurnsLeft--
+// Wait stands still for one turn and lets the robots come.
+func (g *Game) Wait() {
+	if g.GameOver {
 		return
 	}
+	g.record(Action{Kind: ActionWait})
+	g.checkpoint()
+	g.LastMove = Position{}
 
-	for i := range g.Entities {
-		if g.Entities[i].Type != EntityRobot {
-			continue
-		}
+	g.passTurn()
+}
+
+// LastStand keeps waiting until the level is cleared, the player dies or the
+// robots stop moving. Surviving pays a bonus for every robot destroyed while
+// standing.
+func (g *Game) LastStand() {
+	if g.GameOver {
+		return
+	}
+	g.record(Action{Kind: ActionLastStand})
+	g.checkpoint()
+	g.LastMove = Position{}
+
+	level := g.Level
+	destroyed := g.Stats.RobotsDestroyed
 
-		dx := 0
-		dy := 0
+	// Robots only ever close in on a player standing still, so the area of
+	// the arena bounds how long a stand can last.
+	for range g.Width * g.Height {
+		before := g.robotPositions()
+		g.passTurn()
+
+		if g.GameOver || g.Level != level {
+			break
+		}
+		if !g.anyStunned() && slices.Equal(before, g.robotPositions()) {
+			break
+		}
+	}
 
-		if g.Entities[i].Pos.X < g.Player.X {
-			dx = 1
-		} else if g.Entities[i].Pos.X > g.Player.X {
-			dx = -1
+	if !g.GameOver {
+		if kills := g.Stats.RobotsDestroyed - destroyed; kills > 0 {
+			g.addScore(kills*lastStandBonus, ScoreLastStand)
 		}
+	}
+}
 
-		if g.Entities[i].Pos.Y < g.Player.Y {
-			dy = 1
-		} else if g.Entities[i].Pos.Y > g.Player.Y {
-			dy = -1
+func (g *Game) anyStunned() bool {
+	return slices.ContainsFunc(g.Entities, func(e Entity) bool { return e.Type == EntityRobot && e.Stunned > 0 })
+}
+
+// wearOffStuns counts down every stunned robot once a turn has passed.
+func (g *Game) wearOffStuns() {
+	for i := range g.Entities {
+		if g.Entities[i].Type == EntityRobot && g.Entities[i].Stunned > 0 {
+			g.Entities[i].Stunned--
 		}
+	}
+}
 
-		newPos := Position{
-			X: g.Entities[i].Pos.X + dx,
-			Y: g.Entities[i].Pos.Y + dy,
+func (g *Game) robotPositions() []Position {
+	var positions []Position
+	for _, e := range g.Entities {
+		if e.Type == EntityRobot {
+			positions = append(positions, e.Pos)
 		}
+	}
+	return positions
+}
 
-		if newPos.X < 0 || newPos.X >= g.Width || newPos.Y < 0 || newPos.Y >= g.Height {
+// points is what destroying the robot is worth before the kill multiplier.
+func (e Entity) points() int {
+	if e.Fast {
+		return fastRobotPoints
+	}
+	return robotPoints
+}
+
+func (g *Game) MaxHealth() int {
+	return maxHealth
+}
+
+// hitShrub handles the player running into a shrub: the move is blocked but
+// the turn still passes, costing health and score.
+func (g *Game) hitShrub() {
+	g.Health--
+	g.emit(PlayerHurt{Health: g.Health})
+	g.addScore(-shrubPenalty, ScoreShrub)
+	if g.Health <= 0 {
+		g.kill(CauseShrubs)
+		return
+	}
+
+	g.passTurn()
+}
+
+// passTurn lets the robots move, resolves what they ran into and winds down
+// the turn's timers, unless the turn ended the game or the level.
+func (g *Game) passTurn() {
+	level := g.Level
+	g.MoveRobots()
+	g.CheckCollisions()
+	if !g.GameOver && g.Level == level {
+		g.endTurn()
+	}
+}
+
+// MoveRobots moves every robot that is not stunned one step towards the
+// player. Fast robots then take a second step, with collisions from the first
+// resolved in between; callers resolve the collisions of the last step.
+func (g *Game) MoveRobots() {
+	g.Turns++
+	defer g.wearOffStuns()
+
+	g.stepRobots(false)
+
+	if !slices.ContainsFunc(g.Entities, func(e Entity) bool { return e.Type == EntityRobot && e.Fast }) {
+		return
+	}
+	level := g.Level
+	g.CheckCollisions()
+	if g.GameOver || g.Level != level {
+		return
+	}
+	g.stepRobots(true)
+}
+
+// robotMove is where a robot stands and where it is headed this step.
+type robotMove struct {
+	i        int
+	from, to Position
+}
+
+// stepRobots moves robots one step, all at once: every robot picks its move
+// from the same picture of the arena, then the moves are resolved together
+// so the outcome never depends on the order of g.Entities.
+//
+//   - Stunned robots and robots blocked by an obstacle or the edge stay
+//     where they are.
+//   - A robot stepping into junk is wrecked where it stands.
+//   - Two robots swapping cells crash into each other and are both wrecked
+//     where they stand.
+//   - A robot that ends its step on junk, including junk left by a wreck
+//     this step, is destroyed.
+//   - A lone robot entering a shrub crushes it or explodes with it; robots
+//     piling in together crush it and collide.
+//   - Robots sharing a cell collide and leave a single pile of junk.
+//   - Robots trample any pickup where they end up.
+//
+// Cells are resolved in position order so shrub rolls and the kill
+// multiplier come out the same however the robots are stored. Robots that
+// reach the player are left for CheckCollisions.
+func (g *Game) stepRobots(fastOnly bool) {
+	g.field = nil
+
+	var moves []robotMove
+	for i, e := range g.Entities {
+		if e.Type != EntityRobot {
 			continue
 		}
-
-		hitObstacle := false
-		hitJunk := false
-		for _, entity := range g.Entities {
-			if entity.Pos == newPos {
-				if entity.Type == EntityObstacle {
-					hitObstacle = true
-					break
-				}
-				if entity.Type == EntityJunk {
-					hitJunk = true
-					break
-				}
+		m := robotMove{i: i, from: e.Pos, to: e.Pos}
+		if e.Stunned == 0 && (!fastOnly || e.Fast) {
+			step := e.Kind.Behavior().Step(g, e.Pos)
+			to := Position{X: e.Pos.X + step.X, Y: e.Pos.Y + step.Y}
+			if g.inBounds(to) && !g.hasType(to, EntityObstacle) {
+				m.to = to
 			}
 		}
+		moves = append(moves, m)
+	}
+	g.resolveMoves(moves)
+}
 
-		if hitObstacle {
-			continue
+// resolveMoves carries out one step of robot moves picked from the same
+// picture of the arena, by the rules listed on stepRobots.
+func (g *Game) resolveMoves(moves []robotMove) {
+	slices.SortFunc(moves, func(a, b robotMove) int { return comparePositions(a.from, b.from) })
+
+	// Every robot is checked against the junk that was there before the
+	// step, and only then are the wrecked ones turned to junk, so a wreck
+	// cannot catch some of the robots heading for its cell and not others.
+	var wrecked []*robotMove
+	for k := range moves {
+		if m := &moves[k]; m.to != m.from && g.hasType(m.to, EntityJunk) {
+			wrecked = append(wrecked, m)
 		}
+	}
+	for _, m := range wrecked {
+		g.emit(RobotHitJunk{Pos: m.to})
+		m.to = m.from
+		g.wreck(m.i)
+	}
 
-		if hitJunk {
-			g.Entities[i].Type = EntityJunk
-		} else {
-			g.Entities[i].Pos = newPos
+	movingFrom := make(map[Position]*robotMove)
+	for k := range moves {
+		if m := &moves[k]; m.to != m.from {
+			movingFrom[m.from] = m
 		}
 	}
-}
+	for k := range moves {
+		m := &moves[k]
+		other, ok := movingFrom[m.to]
+		if m.to == m.from || !ok || other.to != m.from || comparePositions(m.from, other.from) > 0 {
+			continue
+		}
+		points := g.Entities[m.i].points() + g.Entities[other.i].points()
+		for _, w := range []*robotMove{m, other} {
+			w.to = w.from
+			g.wreck(w.i)
+		}
+		g.emit(RobotsCollided{Pos: m.from, Count: 2})
+		g.scoreKills(2, points)
+	}
 
-func (g *Game) CheckCollisions() {
-	posMap := make(map[Position][]int)
+	var cells []Position
+	for _, m := range moves {
+		if g.Entities[m.i].Type != EntityRobot {
+			continue
+		}
+		if m.to != m.from {
+			g.moveEntity(m.i, m.to)
+		}
+		cells = append(cells, m.to)
+	}
+	slices.SortFunc(cells, comparePositions)
+	cells = slices.Compact(cells)
 
-	for i, entity := range g.Entities {
-		if entity.Type == EntityRobot {
-			posMap[entity.Pos] = append(posMap[entity.Pos], i)
+	removed := make(map[int]bool)
+	var here []int
+	for _, cell := range cells {
+		if cell == g.Player {
+			continue
+		}
 
-			if entity.Pos == g.Player {
-				g.GameOver = true
-				return
+		var robots []int
+		junk, shrub := false, -1
+		here = g.appendAt(here[:0], cell)
+		for _, j := range here {
+			switch {
+			case removed[j]:
+			case g.Entities[j].Type == EntityRobot:
+				robots = append(robots, j)
+			case g.Entities[j].Type == EntityJunk:
+				junk = true
+			case g.Entities[j].Type == EntityShrub:
+				shrub = j
+			case g.Entities[j].Type == EntityPickup:
+				removed[j] = true
+				g.emit(PickupTrampled{Pos: cell, Kind: g.Entities[j].Pickup})
 			}
 		}
-	}
 
-	toRemove := make(map[int]bool)
-	var junkPositions []Position
+		if junk {
+			for _, r := range robots {
+				removed[r] = true
+				g.emit(RobotHitJunk{Pos: cell})
+			}
+			continue
+		}
 
-	for pos, indices := range posMap {
-		if len(indices) > 1 {
-			for _, idx := range indices {
-				toRemove[idx] = true
+		if shrub >= 0 {
+			removed[shrub] = true
+			exploded := len(robots) == 1 && g.rng.IntN(100) < g.Difficulty.ShrubExplodeChance
+			if exploded {
+				g.wreck(robots[0])
 			}
-			junkPositions = append(junkPositions, pos)
+			g.emit(RobotHitShrub{Pos: cell, Exploded: exploded})
+		}
 
-			killCount := len(indices)
-			g.ConsecutiveKills += killCount
-			multiplier := 1 + g.ConsecutiveKills/5
-			g.Score += 10 * killCount * multiplier
+		if len(robots) > 1 {
+			points := 0
+			for _, r := range robots {
+				points += g.Entities[r].points()
+				removed[r] = true
+			}
+			// The first robot becomes the junk pile.
+			delete(removed, robots[0])
+			g.wreck(robots[0])
+			g.emit(RobotsCollided{Pos: cell, Count: len(robots)})
+			g.scoreKills(len(robots), points)
 		}
 	}
 
-	newEntities := []Entity{}
-	for i, entity := range g.Entities {
-		if !toRemove[i] {
-			newEntities = append(newEntities, entity)
+	if len(removed) > 0 {
+		remaining := make([]Entity, 0, len(g.Entities)-len(removed))
+		for i, entity := range g.Entities {
+			if !removed[i] {
+				remaining = append(remaining, entity)
+			}
 		}
+		g.Entities = remaining
 	}
+}
 
-	for _, pos := range junkPositions {
-		newEntities = append(newEntities, Entity{Pos: pos, Type: EntityJunk})
+// wreck turns the robot at g.Entities[i] into junk where it stands. Nothing
+// of the robot is kept, so a pile looks the same whichever robot left it.
+func (g *Game) wreck(i int) {
+	g.Entities[i] = Entity{Pos: g.Entities[i].Pos, Type: EntityJunk}
+}
+
+func comparePositions(a, b Position) int {
+	if a.Y != b.Y {
+		return a.Y - b.Y
 	}
+	return a.X - b.X
+}
 
-	g.Entities = newEntities
+func (g *Game) hasType(p Position, t EntityType) bool {
+	return g.firstAt(p, func(i int) bool { return g.Entities[i].Type == t }) >= 0
+}
 
-	robotCount := 0
-	for _, entity := range g.Entities {
-		if entity.Type == EntityRobot {
-			robotCount++
+// CheckCollisions catches the player if a robot reached them and moves on
+// to the next level once every robot is gone. Robots colliding with each
+// other are resolved as they move.
+func (g *Game) CheckCollisions() {
+	if g.GameOver {
+		return
+	}
+
+	if g.firstAt(g.Player, func(i int) bool { return g.Entities[i].Type == EntityRobot }) >= 0 {
+		if g.ShieldTurns == 0 {
+			g.kill(CauseRobot)
+			return
 		}
+		g.shieldHit()
 	}
 
-	if robotCount == 0 {
-		g.NextLevel()
+	if !slices.ContainsFunc(g.Entities, func(e Entity) bool { return e.Type == EntityRobot }) {
+		g.clearLevel()
 	}
 }
 
+// Teleport drops the player on a random free cell, which may well be next
+// to a robot.
 func (g *Game) Teleport() bool {
-	if g.Teleports <= 0 || g.GameOver {
+	if g.Teleports <= 0 || g.GameOver || g.Targeting() {
 		return false
 	}
-
-	occupiedMap := make(map[Position]bool)
-	for _, entity := range g.Entities {
-		occupiedMap[entity.Pos] = true
-	}
+	g.record(Action{Kind: ActionTeleport})
+	g.checkpoint()
 
 	maxAttempts := 100
 	for range maxAttempts {
 		newPos := Position{
-			X: rand.IntN(g.Width),
-			Y: rand.IntN(g.Height),
+			X: g.rng.IntN(g.Width),
+			Y: g.rng.IntN(g.Height),
 		}
 
-		if !occupiedMap[newPos] {
+		if !g.occupied(newPos) {
 			g.Player = newPos
+			g.LastMove = Position{}
 			g.Teleports--
-			g.Score -= 2
+			g.emit(ToolUsed{Tool: ToolTeleport})
+			g.addScore(-teleportPenalty, ScoreTeleport)
 			return true
 		}
 	}
@@ -321,20 +719,168 @@ func (g *Game) Teleport() bool {
 	return false
 }
 
+// SafeTeleport drops the player on a random free cell no robot can reach
+// after its next move. It fails without using a charge when no such cell
+// exists.
+func (g *Game) SafeTeleport() bool {
+	if g.SafeTeleports <= 0 || g.GameOver || g.Targeting() {
+		return false
+	}
+
+	var landings []Position
+	for y := range g.Height {
+		for x := range g.Width {
+			p := Position{X: x, Y: y}
+			if p != g.Player && !g.occupied(p) && g.SafeLanding(p) {
+				landings = append(landings, p)
+			}
+		}
+	}
+	if len(landings) == 0 {
+		return false
+	}
+
+	g.record(Action{Kind: ActionSafeTeleport})
+	g.checkpoint()
+
+	g.Player = landings[g.rng.IntN(len(landings))]
+	g.LastMove = Position{}
+	g.SafeTeleports--
+	g.emit(ToolUsed{Tool: ToolSafeTeleport})
+	g.addScore(-teleportPenalty, ScoreTeleport)
+	return true
+}
+
+// SafeLanding reports whether a player standing on p would still be out of
+// every robot's reach once the robots have moved. A robot covers its speed
+// in cells each turn, so it must start more than twice that far away.
+func (g *Game) SafeLanding(p Position) bool {
+	for _, e := range g.Entities {
+		if e.Type != EntityRobot {
+			continue
+		}
+		speed := 1
+		if e.Fast {
+			speed = 2
+		}
+		if chebyshev(p, e.Pos) <= 2*speed {
+			return false
+		}
+	}
+	return true
+}
+
+// Targeting reports whether the blaster, blink or EMP wave is being aimed.
+func (g *Game) Targeting() bool {
+	return g.BlasterActive || g.BlinkActive || g.WaveActive
+}
+
+// UseEMP stuns every robot on the board for a few turns.
 func (g *Game) UseEMP() bool {
-	if g.EMPs <= 0 || g.GameOver {
+	if g.EMPs <= 0 || g.GameOver || g.Targeting() {
+		return false
+	}
+	g.record(Action{Kind: ActionEMP})
+	g.checkpoint()
+
+	g.EMPs--
+	g.stun(func(Position) bool { return true }, empTurns)
+	g.emit(ToolUsed{Tool: ToolEMP})
+	return true
+}
+
+// UseEMPPulse stuns the robots around the player, for longer than a
+// board-wide EMP.
+func (g *Game) UseEMPPulse() bool {
+	if g.EMPs <= 0 || g.GameOver || g.Targeting() {
+		return false
+	}
+	g.record(Action{Kind: ActionEMPPulse})
+	g.checkpoint()
+
+	g.EMPs--
+	g.stun(g.InPulse, empPulseTurns)
+	g.emit(ToolUsed{Tool: ToolEMP})
+	return true
+}
+
+func (g *Game) InPulse(p Position) bool {
+	return chebyshev(p, g.Player) <= empPulseRadius
+}
+
+// ToggleWave raises the EMP wave's aim, pointing the way the player last
+// moved, or sends the wave out.
+func (g *Game) ToggleWave() bool {
+	if g.GameOver || (g.Targeting() && !g.WaveActive) {
 		return false
 	}
 
+	if !g.WaveActive {
+		if g.EMPs <= 0 {
+			return false
+		}
+		g.record(Action{Kind: ActionEMPWave})
+		g.WaveActive = true
+		g.WaveDir = g.LastMove
+		if g.WaveDir == (Position{}) {
+			g.WaveDir = Position{X: 0, Y: -1}
+		}
+		return true
+	}
+
+	g.record(Action{Kind: ActionEMPWave})
+	g.checkpoint()
+
+	g.WaveActive = false
 	g.EMPs--
-	g.EMPTurnsLeft = 5
+	g.stun(g.InWave, empWaveTurns)
+	g.emit(ToolUsed{Tool: ToolEMP})
 	return true
 }
 
+// AimWave points the wave in a new direction.
+func (g *Game) AimWave(dx, dy int) {
+	if !g.WaveActive || (dx == 0 && dy == 0) {
+		return
+	}
+	g.record(Action{Kind: ActionEMPWaveAim, DX: dx, DY: dy})
+
+	g.WaveDir = Position{X: sign(dx), Y: sign(dy)}
+}
+
+// InWave reports whether p lies in the quarter of the arena the wave sweeps:
+// a cone opening from the player towards WaveDir, up to empWaveRange cells.
+func (g *Game) InWave(p Position) bool {
+	dx, dy := p.X-g.Player.X, p.Y-g.Player.Y
+	if d := chebyshev(p, g.Player); d == 0 || d > empWaveRange {
+		return false
+	}
+
+	switch dir := g.WaveDir; {
+	case dir.X != 0 && dir.Y != 0:
+		return dx*dir.X >= 0 && dy*dir.Y >= 0
+	case dir.X != 0:
+		return dx*dir.X >= abs(dy)
+	default:
+		return dy*dir.Y >= abs(dx)
+	}
+}
+
+// stun keeps the robots on cells matching in still for turns turns. A
+// longer stun already in place is kept.
+func (g *Game) stun(in func(Position) bool, turns int) {
+	for i := range g.Entities {
+		if e := &g.Entities[i]; e.Type == EntityRobot && in(e.Pos) {
+			e.Stunned = max(e.Stunned, turns)
+		}
+	}
+}
+
 func (g *Game) ToggleBlaster() bool {
-	if g.GameOver {
+	if g.GameOver || (g.Targeting() && !g.BlasterActive) {
 		return false
 	}
+	g.record(Action{Kind: ActionBlaster})
 
 	if !g.BlasterActive {
 		if g.Blasters <= 0 {
@@ -345,37 +891,36 @@ func (g *Game) ToggleBlaster() bool {
 		return true
 	}
 
+	g.checkpoint()
 	g.BlasterActive = false
 	g.Blasters--
+	g.emit(ToolUsed{Tool: ToolBlaster})
 
 	killCount := 0
+	points := 0
 	newEntities := []Entity{}
 
 	for _, entity := range g.Entities {
-		inBlastZone := entity.Pos.X >= g.BlasterTarget.X-1 && entity.Pos.X <= g.BlasterTarget.X+1 &&
-			entity.Pos.Y >= g.BlasterTarget.Y-1 && entity.Pos.Y <= g.BlasterTarget.Y+1
-
-		if entity.Type == EntityRobot && inBlastZone {
+		if entity.Type == EntityRobot && g.InBlastZone(entity.Pos) {
 			killCount++
-		} else {
-			newEntities = append(newEntities, entity)
+			points += entity.points()
+			if !g.Weapon.LeavesJunk {
+				continue
+			}
+			entity = Entity{Pos: entity.Pos, Type: EntityJunk}
 		}
+		newEntities = append(newEntities, entity)
 	}
 
 	g.Entities = newEntities
 
 	if killCount > 0 {
-		g.ConsecutiveKills += killCount
-		multiplier := 1 + g.ConsecutiveKills/5
-		g.Score += 10 * killCount * multiplier
+		g.emit(RobotsBlasted{Target: g.BlasterTarget, Count: killCount})
+		g.scoreKills(killCount, points)
 	}
 
-	playerInBlastZone := g.Player.X >= g.BlasterTarget.X-1 && g.Player.X <= g.BlasterTarget.X+1 &&
-		g.Player.Y >= g.BlasterTarget.Y-1 && g.Player.Y <= g.BlasterTarget.Y+1
-
-	if playerInBlastZone {
-		g.GameOver = true
-		g.SelfDestruct = true
+	if g.InBlastZone(g.Player) {
+		g.kill(CauseSelfDestruct)
 		return true
 	}
 
@@ -387,22 +932,130 @@ func (g *Game) ToggleBlaster() bool {
 	}
 
 	if robotCount == 0 && !g.GameOver {
-		g.NextLevel()
+		g.clearLevel()
 	}
 
 	return true
 }
 
+// InBlastZone reports whether the blaster, fired now, would hit p.
+func (g *Game) InBlastZone(p Position) bool {
+	return g.Weapon.hits(g.Player, g.BlasterTarget, p)
+}
+
+// InBlasterRange reports whether the blaster may be aimed at p.
+func (g *Game) InBlasterRange(p Position) bool {
+	return g.inBounds(p) && g.Weapon.inRange(g.Player, p)
+}
+
+// BlastPreview is what firing the blaster at its current target would do.
+type BlastPreview struct {
+	Kills      int
+	Points     int
+	Multiplier int
+	// ClearsLevel is set when the blast would destroy the last robots and
+	// the player survives it.
+	ClearsLevel bool
+	HitsPlayer  bool
+}
+
+// SimulateBlast works out what firing the blaster now would do, without
+// changing the game.
+func (g *Game) SimulateBlast() BlastPreview {
+	var preview BlastPreview
+	robots, points := 0, 0
+	for _, e := range g.Entities {
+		if e.Type != EntityRobot {
+			continue
+		}
+		robots++
+		if g.InBlastZone(e.Pos) {
+			preview.Kills++
+			points += e.points()
+		}
+	}
+
+	preview.Multiplier = g.killMultiplier(preview.Kills)
+	preview.Points = g.killPoints(preview.Kills, points)
+	preview.HitsPlayer = g.InBlastZone(g.Player)
+	preview.ClearsLevel = robots > 0 && preview.Kills == robots && !preview.HitsPlayer
+	return preview
+}
+
 func (g *Game) MoveBlasterTarget(dx, dy int) {
 	if !g.BlasterActive {
 		return
 	}
+	g.record(Action{Kind: ActionBlasterMove, DX: dx, DY: dy})
+
+	newPos := Position{X: g.BlasterTarget.X + dx, Y: g.BlasterTarget.Y + dy}
+	if g.InBlasterRange(newPos) {
+		g.BlasterTarget = newPos
+	}
+}
+
+// ToggleBlink raises the blink cursor on the player, or jumps to the cell
+// under it. A blink spends a teleport charge.
+func (g *Game) ToggleBlink() bool {
+	if g.GameOver || (g.Targeting() && !g.BlinkActive) {
+		return false
+	}
+
+	if !g.BlinkActive {
+		if g.Teleports <= 0 {
+			return false
+		}
+		g.record(Action{Kind: ActionBlink})
+		g.BlinkActive = true
+		g.BlinkTarget = g.Player
+		return true
+	}
+
+	if !g.CanBlinkTo(g.BlinkTarget) {
+		return false
+	}
+	g.record(Action{Kind: ActionBlink})
+	g.checkpoint()
+
+	g.BlinkActive = false
+	g.Player = g.BlinkTarget
+	g.LastMove = Position{}
+	g.Teleports--
+	g.emit(ToolUsed{Tool: ToolBlink})
+	g.addScore(-teleportPenalty, ScoreTeleport)
+	return true
+}
+
+// CanBlinkTo reports whether p is a free cell within blink range.
+func (g *Game) CanBlinkTo(p Position) bool {
+	return g.InBlinkRange(p) && p != g.Player && !g.occupied(p)
+}
+
+func (g *Game) InBlinkRange(p Position) bool {
+	return g.inBounds(p) && chebyshev(p, g.Player) <= blinkRange
+}
+
+func (g *Game) MoveBlinkTarget(dx, dy int) {
+	if !g.BlinkActive {
+		return
+	}
+	g.record(Action{Kind: ActionBlinkMove, DX: dx, DY: dy})
 
-	newX := g.BlasterTarget.X + dx
-	newY := g.BlasterTarget.Y + dy
+	newPos := Position{X: g.BlinkTarget.X + dx, Y: g.BlinkTarget.Y + dy}
+	if g.InBlinkRange(newPos) {
+		g.BlinkTarget = newPos
+	}
+}
 
-	if newX >= 1 && newX < g.Width-1 && newY >= 1 && newY < g.Height-1 {
-		g.BlasterTarget.X = newX
-		g.BlasterTarget.Y = newY
+// CancelTargeting stops aiming the blaster, blink or EMP wave without using
+// it.
+func (g *Game) CancelTargeting() {
+	if !g.Targeting() {
+		return
 	}
+	g.record(Action{Kind: ActionCancel})
+
+	g.BlasterActive = false
+	g.BlinkActive = false
+	g.WaveActive = false
 }
@@ -0,0 +1,263 @@
+package game
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func robot(x, y int) Entity { return Entity{Pos: Position{X: x, Y: y}, Type: EntityRobot} }
+func fastRobot(x, y int) Entity {
+	return Entity{Pos: Position{X: x, Y: y}, Type: EntityRobot, Fast: true}
+}
+func junk(x, y int) Entity   { return Entity{Pos: Position{X: x, Y: y}, Type: EntityJunk} }
+func shrub(x, y int) Entity  { return Entity{Pos: Position{X: x, Y: y}, Type: EntityShrub} }
+func pickup(x, y int) Entity { return Entity{Pos: Position{X: x, Y: y}, Type: EntityPickup, TTL: 5} }
+
+// newTestGame returns a 20x20 game with the player in the corner and only
+// the given entities on the board.
+func newTestGame(entities ...Entity) *Game {
+	g := NewWithSeed(20, 20, SkillStandard.Difficulty(), 1)
+	g.Player = Position{}
+	g.Entities = entities
+	return g
+}
+
+func compareEntities(a, b Entity) int {
+	if c := comparePositions(a.Pos, b.Pos); c != 0 {
+		return c
+	}
+	if a.Type != b.Type {
+		return int(a.Type) - int(b.Type)
+	}
+	// Robots that reached the player share its cell.
+	if a.Kind != b.Kind {
+		return int(a.Kind) - int(b.Kind)
+	}
+	return cmp.Compare(btoi(a.Fast), btoi(b.Fast))
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedEntities(g *Game) []Entity {
+	entities := slices.Clone(g.Entities)
+	slices.SortFunc(entities, compareEntities)
+	return entities
+}
+
+func TestResolveMoves(t *testing.T) {
+	type step struct{ from, to Position }
+	at := func(x, y int) Position { return Position{X: x, Y: y} }
+
+	tests := []struct {
+		name      string
+		explode   int
+		entities  []Entity
+		steps     []step
+		want      []Entity
+		wantScore int
+	}{
+		{
+			name:     "robots on their own just move",
+			entities: []Entity{robot(3, 3), robot(8, 8)},
+			steps:    []step{{at(3, 3), at(4, 4)}, {at(8, 8), at(7, 7)}},
+			want:     []Entity{robot(4, 4), robot(7, 7)},
+		},
+		{
+			name:      "swapping robots are wrecked where they stand",
+			entities:  []Entity{robot(3, 3), robot(4, 3)},
+			steps:     []step{{at(3, 3), at(4, 3)}, {at(4, 3), at(3, 3)}},
+			want:      []Entity{junk(3, 3), junk(4, 3)},
+			wantScore: 2 * robotPoints,
+		},
+		{
+			name:     "stepping into junk wrecks the robot where it stands",
+			entities: []Entity{robot(3, 3), junk(4, 3)},
+			steps:    []step{{at(3, 3), at(4, 3)}},
+			want:     []Entity{junk(3, 3), junk(4, 3)},
+		},
+		{
+			name:     "robots reaching a fresh wreck are destroyed alike",
+			entities: []Entity{robot(4, 4), robot(5, 5), robot(4, 6), junk(6, 5)},
+			steps:    []step{{at(5, 5), at(6, 5)}, {at(4, 4), at(5, 5)}, {at(4, 6), at(5, 5)}},
+			want:     []Entity{junk(5, 5), junk(6, 5)},
+		},
+		{
+			name:     "following a robot into its cell is not a collision",
+			entities: []Entity{robot(3, 3), robot(4, 3)},
+			steps:    []step{{at(3, 3), at(4, 3)}, {at(4, 3), at(5, 3)}},
+			want:     []Entity{robot(4, 3), robot(5, 3)},
+		},
+		{
+			name:     "a lone robot crushes a shrub",
+			explode:  0,
+			entities: []Entity{robot(3, 3), shrub(4, 3)},
+			steps:    []step{{at(3, 3), at(4, 3)}},
+			want:     []Entity{robot(4, 3)},
+		},
+		{
+			name:     "a lone robot explodes with a shrub",
+			explode:  100,
+			entities: []Entity{robot(3, 3), shrub(4, 3)},
+			steps:    []step{{at(3, 3), at(4, 3)}},
+			want:     []Entity{junk(4, 3)},
+		},
+		{
+			name:      "robots piling into a shrub crush it and collide",
+			explode:   100,
+			entities:  []Entity{robot(3, 3), robot(5, 3), shrub(4, 3)},
+			steps:     []step{{at(3, 3), at(4, 3)}, {at(5, 3), at(4, 3)}},
+			want:      []Entity{junk(4, 3)},
+			wantScore: 2 * robotPoints,
+		},
+		{
+			name:     "a robot tramples a pickup",
+			entities: []Entity{robot(3, 3), pickup(4, 3)},
+			steps:    []step{{at(3, 3), at(4, 3)}},
+			want:     []Entity{robot(4, 3)},
+		},
+		{
+			name:      "a pile-up leaves one heap of junk",
+			entities:  []Entity{robot(3, 3), robot(5, 3), robot(4, 2), fastRobot(4, 4)},
+			steps:     []step{{at(3, 3), at(4, 3)}, {at(5, 3), at(4, 3)}, {at(4, 2), at(4, 3)}, {at(4, 4), at(4, 3)}},
+			want:      []Entity{junk(4, 3)},
+			wantScore: 3*robotPoints + fastRobotPoints,
+		},
+		{
+			name:      "robots piling onto a standing robot collide with it",
+			entities:  []Entity{robot(3, 3), robot(4, 3)},
+			steps:     []step{{at(3, 3), at(4, 3)}},
+			want:      []Entity{junk(4, 3)},
+			wantScore: 2 * robotPoints,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewPCG(1, 2))
+			for shuffle := range 20 {
+				entities := slices.Clone(tt.entities)
+				if shuffle > 0 {
+					rng.Shuffle(len(entities), func(i, j int) { entities[i], entities[j] = entities[j], entities[i] })
+				}
+				g := newTestGame(entities...)
+				g.Difficulty.ShrubExplodeChance = tt.explode
+
+				var moves []robotMove
+				for i, e := range g.Entities {
+					if e.Type != EntityRobot {
+						continue
+					}
+					m := robotMove{i: i, from: e.Pos, to: e.Pos}
+					for _, s := range tt.steps {
+						if s.from == e.Pos {
+							m.to = s.to
+						}
+					}
+					moves = append(moves, m)
+				}
+				g.resolveMoves(moves)
+
+				want := slices.Clone(tt.want)
+				slices.SortFunc(want, compareEntities)
+				if got := sortedEntities(g); !slices.Equal(got, want) {
+					t.Fatalf("order %v: entities = %v, want %v", entities, got, want)
+				}
+				if g.Score != tt.wantScore {
+					t.Fatalf("order %v: score = %d, want %d", entities, g.Score, tt.wantScore)
+				}
+			}
+		})
+	}
+}
+
+// TestStepRobotsIgnoresEntityOrder plays real arenas, with every robot kind
+// and fast robots, from shuffled copies of the same board.
+func TestStepRobotsIgnoresEntityOrder(t *testing.T) {
+	for seed := range uint64(50) {
+		var want []Entity
+		var wantScore int
+		for shuffle := range 5 {
+			g := NewWithSeed(30, 20, SkillNightmare.Difficulty(), seed)
+			for range 4 {
+				g.NextLevel()
+			}
+			if shuffle > 0 {
+				rng := rand.New(rand.NewPCG(seed, uint64(shuffle)))
+				rng.Shuffle(len(g.Entities), func(i, j int) { g.Entities[i], g.Entities[j] = g.Entities[j], g.Entities[i] })
+			}
+
+			for range 3 {
+				g.stepRobots(false)
+			}
+
+			got := sortedEntities(g)
+			if shuffle == 0 {
+				want, wantScore = got, g.Score
+				continue
+			}
+			if !slices.Equal(got, want) || g.Score != wantScore {
+				t.Fatalf("seed %d: shuffled board ended differently", seed)
+			}
+		}
+	}
+}
+
+// TestCrowdedArenaSpawns checks that an arena too small for a level's
+// robots gets as many as fit rather than spawning forever.
+func TestCrowdedArenaSpawns(t *testing.T) {
+	for _, skill := range Skills {
+		g := NewWithSeed(10, 5, skill.Difficulty(), 1)
+		g.Level = 99
+		g.NextLevel()
+
+		seen := map[Position]bool{g.Player: true}
+		for _, e := range g.Entities {
+			if seen[e.Pos] {
+				t.Fatalf("%v: two things spawned at %v", skill, e.Pos)
+			}
+			seen[e.Pos] = true
+		}
+
+		// There were more robots than room, so every cell far enough from
+		// the player must have been used.
+		_, _, _, _, minDist := skill.Difficulty().levelCounts(g.Level)
+		for y := range g.Height {
+			for x := range g.Width {
+				p := Position{X: x, Y: y}
+				if !seen[p] && isFarEnough(p, []Position{g.Player}, minDist) {
+					t.Fatalf("%v: %v was left empty", skill, p)
+				}
+			}
+		}
+	}
+}
+
+func TestSwapIsOneCollision(t *testing.T) {
+	g := newTestGame(robot(3, 3), robot(4, 3))
+	var collisions []RobotsCollided
+	g.Subscribe(func(e Event) {
+		if c, ok := e.(RobotsCollided); ok {
+			collisions = append(collisions, c)
+		}
+	})
+
+	g.resolveMoves([]robotMove{
+		{i: 0, from: Position{X: 3, Y: 3}, to: Position{X: 4, Y: 3}},
+		{i: 1, from: Position{X: 4, Y: 3}, to: Position{X: 3, Y: 3}},
+	})
+
+	want := []RobotsCollided{{Pos: Position{X: 3, Y: 3}, Count: 2}}
+	if !slices.Equal(collisions, want) {
+		t.Fatalf("collisions = %v, want %v", collisions, want)
+	}
+	if got := g.Stats.Levels[0].RobotsDestroyed; got != 2 {
+		t.Fatalf("stats count %d robots destroyed, want 2", got)
+	}
+}
@@ -0,0 +1,200 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Level is a hand-designed arena. Tool counts left nil keep whatever the
+// player carries in from the previous level.
+type Level struct {
+	Name          string
+	Width         int
+	Height        int
+	Player        Position
+	Entities      []Entity
+	Teleports     *int
+	SafeTeleports *int
+	EMPs          *int
+	Blasters      *int
+}
+
+//go:embed levels/*.txt
+var levelFiles embed.FS
+
+// levelGlyphs maps the grid's characters onto what they place. Walls and
+// obstacles are the same thing to the engine.
+var levelGlyphs = map[rune]Entity{
+	'#': {Type: EntityObstacle},
+	'&': {Type: EntityShrub},
+	'*': {Type: EntityJunk},
+	'R': {Type: EntityRobot, Kind: RobotGreedy},
+	'P': {Type: EntityRobot, Kind: RobotPathfinder},
+	'F': {Type: EntityRobot, Kind: RobotFlanker},
+	'C': {Type: EntityRobot, Kind: RobotCautious},
+	'X': {Type: EntityRobot, Fast: true},
+}
+
+// ParseLevel reads a level file: "key: value" header lines, a blank line,
+// then the arena one row per line. In the arena '.' is an empty cell, '@'
+// the player's start and the rest are listed in levelGlyphs.
+func ParseLevel(data []byte) (Level, error) {
+	var l Level
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			break
+		}
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return Level{}, fmt.Errorf("line %d: %q is not a key: value header", line, text)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if key == "name" {
+			l.Name = value
+			continue
+		}
+		var tool **int
+		switch key {
+		case "teleports":
+			tool = &l.Teleports
+		case "safe_teleports":
+			tool = &l.SafeTeleports
+		case "emps":
+			tool = &l.EMPs
+		case "blasters":
+			tool = &l.Blasters
+		default:
+			return Level{}, fmt.Errorf("line %d: unknown header %q", line, key)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return Level{}, fmt.Errorf("line %d: %s: %q must be a non-negative number", line, key, value)
+		}
+		*tool = &n
+	}
+
+	players := 0
+	for scanner.Scan() {
+		line++
+		row := strings.TrimRight(scanner.Text(), " \t\r")
+		if row == "" {
+			continue
+		}
+		cells := []rune(row)
+		if l.Width == 0 {
+			l.Width = len(cells)
+		} else if len(cells) != l.Width {
+			return Level{}, fmt.Errorf("line %d: row is %d cells wide, want %d", line, len(cells), l.Width)
+		}
+
+		for x, c := range cells {
+			pos := Position{X: x, Y: l.Height}
+			switch c {
+			case '.':
+			case '@':
+				l.Player = pos
+				players++
+			default:
+				e, ok := levelGlyphs[c]
+				if !ok {
+					return Level{}, fmt.Errorf("line %d: unknown cell %q", line, c)
+				}
+				e.Pos = pos
+				l.Entities = append(l.Entities, e)
+			}
+		}
+		l.Height++
+	}
+	if err := scanner.Err(); err != nil {
+		return Level{}, err
+	}
+
+	if l.Height == 0 {
+		return Level{}, fmt.Errorf("level has no arena")
+	}
+	if players != 1 {
+		return Level{}, fmt.Errorf("level needs exactly one player start, has %d", players)
+	}
+	if !slices.ContainsFunc(l.Entities, func(e Entity) bool { return e.Type == EntityRobot }) {
+		return Level{}, fmt.Errorf("level has no robots")
+	}
+	return l, nil
+}
+
+// CampaignLevels returns the bundled levels in the order they are played,
+// which is the order of their file names.
+var CampaignLevels = sync.OnceValue(func() []Level {
+	names, err := levelFiles.ReadDir("levels")
+	if err != nil {
+		panic(err)
+	}
+
+	var levels []Level
+	for _, entry := range names {
+		data, err := levelFiles.ReadFile(path.Join("levels", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		l, err := ParseLevel(data)
+		if err != nil {
+			panic(fmt.Sprintf("bundled level %s: %v", entry.Name(), err))
+		}
+		levels = append(levels, l)
+	}
+	return levels
+})
+
+// campaignLevel returns the bundled level for g.Level, if the game is a
+// campaign that has not run out of levels yet.
+func (g *Game) campaignLevel() (Level, bool) {
+	levels := CampaignLevels()
+	if !g.Difficulty.Campaign || g.Level > len(levels) {
+		return Level{}, false
+	}
+	return levels[g.Level-1], true
+}
+
+// setupLevel lays out the arena for g.Level: the campaign's level while
+// there is one, a random arena otherwise. Random arenas are the size the
+// game started with, whatever size the last campaign level was.
+func (g *Game) setupLevel() {
+	l, ok := g.campaignLevel()
+	if !ok {
+		g.LevelName = ""
+		g.Width, g.Height = g.ArenaWidth, g.ArenaHeight
+		g.Player = Position{X: g.Width / 2, Y: g.Height / 2}
+		g.Entities = g.spawnEntities(g.Difficulty.levelCounts(g.Level))
+		return
+	}
+
+	g.LevelName = l.Name
+	g.Width, g.Height = l.Width, l.Height
+	g.Player = l.Player
+	g.Entities = slices.Clone(l.Entities)
+	for _, tool := range []struct {
+		count *int
+		set   *int
+	}{
+		{&g.Teleports, l.Teleports},
+		{&g.SafeTeleports, l.SafeTeleports},
+		{&g.EMPs, l.EMPs},
+		{&g.Blasters, l.Blasters},
+	} {
+		if tool.set != nil {
+			*tool.count = *tool.set
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestBundledLevelsParse(t *testing.T) {
+	entries, err := levelFiles.ReadDir("levels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("no bundled levels")
+	}
+	for _, entry := range entries {
+		data, err := levelFiles.ReadFile(path.Join("levels", entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := ParseLevel(data)
+		if err != nil {
+			t.Errorf("%s: %v", entry.Name(), err)
+			continue
+		}
+		if l.Name == "" {
+			t.Errorf("%s: no name", entry.Name())
+		}
+	}
+}
+
+func TestParseLevelErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		level string
+		err   string
+	}{
+		{"ragged rows", "name: x\n\n@..\n.R\n", "row is 2 cells wide, want 3"},
+		{"no player", "name: x\n\n...\n.R.\n", "exactly one player start, has 0"},
+		{"two players", "name: x\n\n@..\n.R@\n", "exactly one player start, has 2"},
+		{"no robots", "name: x\n\n@..\n.#&\n", "no robots"},
+		{"unknown header", "name: x\nlives: 3\n\n@.R\n", `unknown header "lives"`},
+		{"bad tool count", "teleports: -1\n\n@.R\n", "must be a non-negative number"},
+		{"unknown glyph", "name: x\n\n@.R\n.?.\n", `unknown cell '?'`},
+		{"no arena", "name: x\n\n", "no arena"},
+	} {
+		_, err := ParseLevel([]byte(tt.level))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want one containing %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestCampaignReturnsToArenaSize(t *testing.T) {
+	d := SkillStandard.Difficulty()
+	d.Campaign = true
+	g := NewWithSeed(60, 30, d, 1)
+
+	levels := CampaignLevels()
+	if g.Width != levels[0].Width || g.Height != levels[0].Height {
+		t.Fatalf("level 1 is %dx%d, want the level file's %dx%d", g.Width, g.Height, levels[0].Width, levels[0].Height)
+	}
+	for range levels {
+		g.NextLevel()
+	}
+	if g.LevelName != "" || g.Width != 60 || g.Height != 30 {
+		t.Fatalf("after the campaign: %q at %dx%d, want a random 60x30 arena", g.LevelName, g.Width, g.Height)
+	}
+
+	// The size survives a save taken during the campaign.
+	g = NewWithSeed(60, 30, d, 1)
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resumed Game
+	if err := json.Unmarshal(data, &resumed); err != nil {
+		t.Fatal(err)
+	}
+	resumed.Level = len(levels)
+	resumed.NextLevel()
+	if resumed.Width != 60 || resumed.Height != 30 {
+		t.Fatalf("resumed game went on at %dx%d, want 60x30", resumed.Width, resumed.Height)
+	}
+}
@@ -0,0 +1,132 @@
+package game
+
+type PickupKind int
+
+const (
+	PickupTeleport PickupKind = iota
+	PickupEMP
+	PickupBlaster
+	PickupShield
+	PickupMultiplier
+)
+
+var pickupKinds = []PickupKind{PickupTeleport, PickupEMP, PickupBlaster, PickupShield, PickupMultiplier}
+
+func (k PickupKind) String() string {
+	switch k {
+	case PickupTeleport:
+		return "teleport"
+	case PickupEMP:
+		return "emp"
+	case PickupBlaster:
+		return "blaster"
+	case PickupShield:
+		return "shield"
+	case PickupMultiplier:
+		return "multiplier"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	shieldTurns = 10
+	boostTurns  = 10
+)
+
+// collect hands the player the pickup at g.Entities[i] and takes it off the
+// board.
+func (g *Game) collect(i int) {
+	e := g.Entities[i]
+	switch e.Pickup {
+	case PickupTeleport:
+		g.Teleports++
+	case PickupEMP:
+		g.EMPs++
+	case PickupBlaster:
+		g.Blasters++
+	case PickupShield:
+		g.ShieldTurns = shieldTurns
+	case PickupMultiplier:
+		g.BoostTurns = boostTurns
+	}
+	g.removeEntities(func(j int) bool { return j == i })
+	g.emit(PickupCollected{Pos: e.Pos, Kind: e.Pickup})
+}
+
+// shieldHit wrecks the robots that reached a shielded player. The shield
+// breaks on the first hit.
+func (g *Game) shieldHit() {
+	killCount, points := 0, 0
+	g.removeEntities(func(i int) bool {
+		e := g.Entities[i]
+		if e.Type != EntityRobot || e.Pos != g.Player {
+			return false
+		}
+		killCount++
+		points += e.points()
+		return true
+	})
+	g.ShieldTurns = 0
+	g.emit(ShieldHit{Count: killCount})
+	g.scoreKills(killCount, points)
+}
+
+// endTurn runs down the timers that count turns the player has taken:
+// shield, score boost and pickups, and drops a new pickup when one is due.
+func (g *Game) endTurn() {
+	g.ShieldTurns = max(0, g.ShieldTurns-1)
+	g.BoostTurns = max(0, g.BoostTurns-1)
+
+	expired := false
+	for i := range g.Entities {
+		if e := &g.Entities[i]; e.Type == EntityPickup {
+			e.TTL--
+			expired = expired || e.TTL <= 0
+		}
+	}
+	if expired {
+		g.removeEntities(func(i int) bool {
+			return g.Entities[i].Type == EntityPickup && g.Entities[i].TTL <= 0
+		})
+	}
+
+	if g.Difficulty.PickupEvery == 0 {
+		return
+	}
+	g.PickupIn--
+	if g.PickupIn > 0 {
+		return
+	}
+	g.PickupIn = g.Difficulty.PickupEvery
+
+	var free []Position
+	for y := range g.Height {
+		for x := range g.Width {
+			if p := (Position{X: x, Y: y}); p != g.Player && !g.occupied(p) {
+				free = append(free, p)
+			}
+		}
+	}
+	if len(free) == 0 {
+		return
+	}
+	g.Entities = append(g.Entities, Entity{
+		Pos:    free[g.rng.IntN(len(free))],
+		Type:   EntityPickup,
+		Pickup: pickupKinds[g.rng.IntN(len(pickupKinds))],
+		TTL:    g.Difficulty.PickupTTL,
+	})
+}
+
+// removeEntities drops the entities whose index matches into a new slice,
+// so the occupancy index notices.
+func (g *Game) removeEntities(match func(i int) bool) {
+	remaining := make([]Entity, 0, len(g.Entities))
+	for i, e := range g.Entities {
+		if !match(i) {
+			remaining = append(remaining, e)
+		}
+	}
+	g.Entities = remaining
+}
@@ -0,0 +1,33 @@
+package game
+
+import "testing"
+
+func TestShieldedPlayerRamsRobot(t *testing.T) {
+	g := newTestGame(robot(1, 0), robot(19, 19))
+	g.ShieldTurns = 5
+
+	g.MovePlayer(1, 0)
+
+	if g.GameOver {
+		t.Fatalf("shielded player died: %v", g.DeathCause)
+	}
+	if g.Player != (Position{X: 1, Y: 0}) {
+		t.Fatalf("player at %v, want (1,0)", g.Player)
+	}
+	if g.ShieldTurns != 0 {
+		t.Fatalf("shield has %d turns left, want it broken", g.ShieldTurns)
+	}
+	if n := countType(g, EntityRobot); n != 1 {
+		t.Fatalf("%d robots left, want 1", n)
+	}
+	if g.Score != robotPoints {
+		t.Fatalf("score = %d, want %d", g.Score, robotPoints)
+	}
+
+	// Without the shield the same move is fatal.
+	g = newTestGame(robot(1, 0), robot(19, 19))
+	g.MovePlayer(1, 0)
+	if !g.GameOver || g.DeathCause != CauseRobot {
+		t.Fatalf("unshielded player: game over %v, cause %v", g.GameOver, g.DeathCause)
+	}
+}
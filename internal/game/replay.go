@@ -0,0 +1,194 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ActionKind string
+
+const (
+	ActionMove         ActionKind = "m"
+	ActionTeleport     ActionKind = "t"
+	ActionSafeTeleport ActionKind = "s"
+	ActionBlink        ActionKind = "k"
+	ActionBlinkMove    ActionKind = "j"
+	ActionEMP          ActionKind = "e"
+	ActionEMPPulse     ActionKind = "p"
+	ActionEMPWave      ActionKind = "v"
+	ActionEMPWaveAim   ActionKind = "i"
+	ActionBlaster      ActionKind = "b"
+	ActionBlasterMove  ActionKind = "a"
+	ActionCancel       ActionKind = "c"
+	ActionWait         ActionKind = "w"
+	ActionLastStand    ActionKind = "l"
+	ActionUndo         ActionKind = "u"
+)
+
+type Action struct {
+	Kind ActionKind `json:"k"`
+	DX   int        `json:"x,omitempty"`
+	DY   int        `json:"y,omitempty"`
+}
+
+// Replay is everything needed to re-drive a run: the engine is deterministic
+// for a given seed, so the arena setup and the ordered actions are enough.
+type Replay struct {
+	Seed       uint64     `json:"seed"`
+	Width      int        `json:"width"`
+	Height     int        `json:"height"`
+	Difficulty Difficulty `json:"difficulty"`
+	Actions    []Action   `json:"actions"`
+}
+
+func (g *Game) record(a Action) {
+	g.actions = append(g.actions, a)
+}
+
+func (g *Game) Replay() Replay {
+	actions := make([]Action, len(g.actions))
+	copy(actions, g.actions)
+
+	return Replay{
+		Seed:       g.Seed,
+		Width:      g.Width,
+		Height:     g.Height,
+		Difficulty: g.Difficulty,
+		Actions:    actions,
+	}
+}
+
+// Start returns a fresh game in the state the recorded run began in.
+func (r Replay) Start() *Game {
+	return NewWithSeed(r.Width, r.Height, r.Difficulty, r.Seed)
+}
+
+func (a Action) Apply(g *Game) {
+	switch a.Kind {
+	case ActionMove:
+		g.MovePlayer(a.DX, a.DY)
+	case ActionTeleport:
+		g.Teleport()
+	case ActionSafeTeleport:
+		g.SafeTeleport()
+	case ActionBlink:
+		g.ToggleBlink()
+	case ActionBlinkMove:
+		g.MoveBlinkTarget(a.DX, a.DY)
+	case ActionEMP:
+		g.UseEMP()
+	case ActionEMPPulse:
+		g.UseEMPPulse()
+	case ActionEMPWave:
+		g.ToggleWave()
+	case ActionEMPWaveAim:
+		g.AimWave(a.DX, a.DY)
+	case ActionBlaster:
+		g.ToggleBlaster()
+	case ActionBlasterMove:
+		g.MoveBlasterTarget(a.DX, a.DY)
+	case ActionCancel:
+		g.CancelTargeting()
+	case ActionWait:
+		g.Wait()
+	case ActionLastStand:
+		g.LastStand()
+	case ActionUndo:
+		g.Undo()
+	}
+}
+
+// SaveReplay writes r to a new file in dir and returns its path, then
+// deletes the oldest replays beyond keep, or none when keep is 0. The path
+// is returned even if clearing out old replays fails.
+func SaveReplay(dir, name string, r Replay, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, replayFileName(name))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, pruneReplays(dir, keep)
+}
+
+// pruneReplays deletes all but the newest keep replays in dir. Sessions
+// ending together may prune at once, so files already gone are fine.
+func pruneReplays(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type replayFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []replayFile
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, replayFile{entry.Name(), info.ModTime()})
+	}
+	if len(files) <= keep {
+		return nil
+	}
+
+	slices.SortFunc(files, func(a, b replayFile) int { return b.modTime.Compare(a.modTime) })
+	for _, f := range files[keep:] {
+		if err := os.Remove(filepath.Join(dir, f.name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func LoadReplay(path string) (Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Replay{}, err
+	}
+
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Replay{}, err
+	}
+	return r, nil
+}
+
+func replayFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if safe == "" {
+		safe = "player"
+	}
+	return safe + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + ".json"
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSaveReplayKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	r := NewWithSeed(20, 10, SkillStandard.Difficulty(), 1).Replay()
+
+	start := time.Now().Add(-time.Hour)
+	var old []string
+	for i := range 4 {
+		path, err := SaveReplay(dir, "old", r, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		at := start.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+		old = append(old, filepath.Base(path))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := SaveReplay(dir, "new", r, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := []string{old[2], old[3], filepath.Base(path), "notes.txt"}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Fatalf("kept %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package game
+
+type RobotKind int
+
+const (
+	RobotGreedy RobotKind = iota
+	RobotPathfinder
+	RobotFlanker
+	RobotCautious
+)
+
+func (k RobotKind) String() string {
+	switch k {
+	case RobotGreedy:
+		return "greedy"
+	case RobotPathfinder:
+		return "pathfinder"
+	case RobotFlanker:
+		return "flanker"
+	case RobotCautious:
+		return "cautious"
+	default:
+		return "unknown"
+	}
+}
+
+// Behavior decides which way a robot standing at from steps this turn. The
+// step is a delta of at most one cell on each axis; zero stays put.
+type Behavior interface {
+	Step(g *Game, from Position) Position
+}
+
+func (k RobotKind) Behavior() Behavior {
+	switch k {
+	case RobotPathfinder:
+		return Pathfinder{}
+	case RobotFlanker:
+		return Flanker{}
+	case RobotCautious:
+		return Cautious{}
+	default:
+		return Greedy{}
+	}
+}
+
+// RobotMix brings in a kind of robot from a level on, in place of Percent of
+// the greedy robots.
+type RobotMix struct {
+	Kind      RobotKind
+	FromLevel int
+	Percent   int
+}
+
+// robotKinds returns the kind of each of count robots spawning on level.
+func (d Difficulty) robotKinds(level, count int) []RobotKind {
+	kinds := make([]RobotKind, 0, count)
+	for _, mix := range d.RobotMix {
+		if level < mix.FromLevel {
+			continue
+		}
+		for range min(count*mix.Percent/100, count-len(kinds)) {
+			kinds = append(kinds, mix.Kind)
+		}
+	}
+	for len(kinds) < count {
+		kinds = append(kinds, RobotGreedy)
+	}
+	return kinds
+}
+
+// Greedy heads straight for the player and stops dead at obstacles.
+type Greedy struct{}
+
+func (Greedy) Step(g *Game, from Position) Position {
+	return toward(from, g.Player)
+}
+
+// Pathfinder follows the shortest route to the player around obstacles and
+// junk, and charges straight in like a greedy robot when there is none.
+type Pathfinder struct{}
+
+func (Pathfinder) Step(g *Game, from Position) Position {
+	dist := g.distances()
+	here := dist[from.Y*g.Width+from.X]
+	if here < 0 {
+		return toward(from, g.Player)
+	}
+
+	// Of the steps along a shortest route, take the one closest to the
+	// player as the crow flies so robots in the open still move greedily.
+	best, bestDist, bestCrow := Position{}, here, 0
+	for _, d := range neighbors {
+		p := Position{X: from.X + d.X, Y: from.Y + d.Y}
+		if !g.inBounds(p) {
+			continue
+		}
+		n := dist[p.Y*g.Width+p.X]
+		if n < 0 || n >= here {
+			continue
+		}
+		crow := crowDist(p, g.Player)
+		if best == (Position{}) || n < bestDist || n == bestDist && crow < bestCrow {
+			best, bestDist, bestCrow = d, n, crow
+		}
+	}
+	return best
+}
+
+func crowDist(a, b Position) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx*dx + dy*dy
+}
+
+// flankLead is how many of the player's moves ahead a flanker aims.
+const flankLead = 3
+
+// Flanker aims where the player will be if they keep moving the same way,
+// closing in directly once it is near.
+type Flanker struct{}
+
+func (Flanker) Step(g *Game, from Position) Position {
+	if chebyshev(from, g.Player) <= flankLead {
+		return toward(from, g.Player)
+	}
+	target := Position{
+		X: min(max(g.Player.X+g.LastMove.X*flankLead, 0), g.Width-1),
+		Y: min(max(g.Player.Y+g.LastMove.Y*flankLead, 0), g.Height-1),
+	}
+	return toward(from, target)
+}
+
+// Cautious chases like a greedy robot but never walks into junk, sidestepping
+// along one axis or waiting instead.
+type Cautious struct{}
+
+func (Cautious) Step(g *Game, from Position) Position {
+	d := toward(from, g.Player)
+	for _, step := range []Position{d, {X: d.X}, {Y: d.Y}} {
+		if step == (Position{}) {
+			continue
+		}
+		if !g.hasType(Position{X: from.X + step.X, Y: from.Y + step.Y}, EntityJunk) {
+			return step
+		}
+	}
+	return Position{}
+}
+
+var neighbors = []Position{
+	{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1},
+	{X: -1, Y: 0}, {X: 1, Y: 0},
+	{X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1},
+}
+
+func toward(from, to Position) Position {
+	return Position{X: sign(to.X - from.X), Y: sign(to.Y - from.Y)}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func chebyshev(a, b Position) int {
+	return max(abs(a.X-b.X), abs(a.Y-b.Y))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (g *Game) inBounds(p Position) bool {
+	return p.X >= 0 && p.X < g.Width && p.Y >= 0 && p.Y < g.Height
+}
+
+// distances is every cell's distance in robot moves from the player, routing
+// around obstacles and junk, or -1 where the player cannot be reached. It is
+// worked out once per turn and shared by every pathfinder.
+func (g *Game) distances() []int {
+	if g.field != nil {
+		return g.field
+	}
+
+	// Blocked cells start out marked as visited so the search skips them,
+	// then go back to unreachable once it is done.
+	const blocked = -2
+	dist := make([]int, g.Width*g.Height)
+	for i := range dist {
+		dist[i] = -1
+	}
+	for _, e := range g.Entities {
+		if (e.Type == EntityObstacle || e.Type == EntityJunk) && g.inBounds(e.Pos) {
+			dist[e.Pos.Y*g.Width+e.Pos.X] = blocked
+		}
+	}
+
+	queue := make([]int, 0, len(dist))
+	start := g.Player.Y*g.Width + g.Player.X
+	dist[start] = 0
+	queue = append(queue, start)
+	for head := 0; head < len(queue); head++ {
+		c := queue[head]
+		x, y := c%g.Width, c/g.Width
+		for _, d := range neighbors {
+			nx, ny := x+d.X, y+d.Y
+			if nx < 0 || nx >= g.Width || ny < 0 || ny >= g.Height {
+				continue
+			}
+			n := ny*g.Width + nx
+			if dist[n] != -1 {
+				continue
+			}
+			dist[n] = dist[c] + 1
+			queue = append(queue, n)
+		}
+	}
+	for i, d := range dist {
+		if d == blocked {
+			dist[i] = -1
+		}
+	}
+
+	g.field = dist
+	return dist
+}
@@ -0,0 +1,170 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// gameFields has Game's fields without its methods, so encoding it does not
+// recurse into Game's own MarshalJSON.
+type gameFields Game
+
+type savedGame struct {
+	*gameFields
+	RNG     []byte   `json:"rng"`
+	Actions []Action `json:"actions"`
+}
+
+// MarshalJSON encodes everything needed to carry on playing, including the
+// random source and the actions so far, so a resumed run still replays from
+// its seed. Undo history is not kept.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	rng, err := g.src.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(savedGame{gameFields: (*gameFields)(g), RNG: rng, Actions: g.actions})
+}
+
+func (g *Game) UnmarshalJSON(data []byte) error {
+	s := savedGame{gameFields: (*gameFields)(g)}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	g.src = &rand.PCG{}
+	if err := g.src.UnmarshalBinary(s.RNG); err != nil {
+		return err
+	}
+	g.rng = rand.New(g.src)
+	g.actions = s.Actions
+	g.history = nil
+	// Games saved before the arena size was kept started at their current
+	// size.
+	if g.ArenaWidth == 0 {
+		g.ArenaWidth, g.ArenaHeight = g.Width, g.Height
+	}
+	return nil
+}
+
+// SaveStore keeps one unfinished game per player so a dropped connection
+// does not end the run.
+type SaveStore interface {
+	Save(player string, g *Game) error
+	// Load returns the player's saved game, or nil if there is none.
+	Load(player string) (*Game, error)
+	Delete(player string) error
+}
+
+// FileSaveStore writes each player's game to its own file in dir.
+type FileSaveStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewFileSaveStore(dir string) *FileSaveStore {
+	return &FileSaveStore{dir: dir}
+}
+
+func (s *FileSaveStore) path(player string) string {
+	return filepath.Join(s.dir, url.PathEscape(player)+".json")
+}
+
+func (s *FileSaveStore) Save(player string, g *Game) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFileAtomic(s.path(player), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (s *FileSaveStore) Load(player string) (*Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(player))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Game{}
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *FileSaveStore) Delete(player string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(player))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// MemorySaveStore keeps games encoded, so a saved game does not change when
+// the session that saved it carries on.
+type MemorySaveStore struct {
+	mu    sync.Mutex
+	games map[string][]byte
+}
+
+func NewMemorySaveStore() *MemorySaveStore {
+	return &MemorySaveStore{games: make(map[string][]byte)}
+}
+
+func (s *MemorySaveStore) Save(player string, g *Game) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.games[player] = data
+	return nil
+}
+
+func (s *MemorySaveStore) Load(player string) (*Game, error) {
+	s.mu.Lock()
+	data, ok := s.games[player]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+	g := &Game{}
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *MemorySaveStore) Delete(player string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.games, player)
+	return nil
+}
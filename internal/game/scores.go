@@ -2,64 +2,117 @@ package game
 
 import (
 	"bufio"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type ScoreEntry struct {
-	Name  string
-	Level int
-	Score int
+	Name        string
+	Level       int
+	Score       int
+	Skill       Skill
+	Fingerprint string
+	// Undos is how many turns were taken back on the way to the score.
+	Undos int
 }
 
-const scoresFile = "scores.txt"
+const maxScores = 10
 
-func SaveScore(name string, level, score int) error {
-	scores, _ := LoadScores()
-	
+// ScoreStore keeps the leaderboard: the best entry per name, highest first.
+type ScoreStore interface {
+	Save(entry ScoreEntry) error
+	Load() ([]ScoreEntry, error)
+}
+
+func TopScores(store ScoreStore, n int) ([]ScoreEntry, error) {
+	scores, err := store.Load()
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	return scores, err
+}
+
+// mergeScore records entry if it beats the existing best for its name and
+// returns the leaderboard sorted and trimmed.
+func mergeScore(scores []ScoreEntry, entry ScoreEntry) []ScoreEntry {
 	found := false
 	for i, s := range scores {
-		if s.Name == name {
+		if s.Name == entry.Name {
 			found = true
-			if score > s.Score {
-				scores[i] = ScoreEntry{Name: name, Level: level, Score: score}
+			if entry.Score > s.Score {
+				scores[i] = entry
 			}
 			break
 		}
 	}
-	
+
 	if !found {
-		scores = append(scores, ScoreEntry{Name: name, Level: level, Score: score})
+		scores = append(scores, entry)
+	}
+
+	sortScores(scores)
+
+	if len(scores) > maxScores {
+		scores = scores[:maxScores]
 	}
+	return scores
+}
 
-	sort.Slice(scores, func(i, j int) bool {
+func sortScores(scores []ScoreEntry) {
+	sort.SliceStable(scores, func(i, j int) bool {
 		return scores[i].Score > scores[j].Score
 	})
+}
 
-	if len(scores) > 10 {
-		scores = scores[:10]
-	}
+// FileScoreStore is safe to share between sessions: writes are serialized
+// in-process by a mutex and across processes by a lock file, and the file is
+// replaced atomically so a crash never leaves it truncated.
+type FileScoreStore struct {
+	mu   sync.Mutex
+	path string
+}
 
-	f, err := os.Create(scoresFile)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+func NewFileScoreStore(path string) *FileScoreStore {
+	return &FileScoreStore{path: path}
+}
 
-	for _, s := range scores {
-		f.WriteString(s.Name + "|" + strconv.Itoa(s.Level) + "|" + strconv.Itoa(s.Score) + "\n")
-	}
+func (s *FileScoreStore) Save(entry ScoreEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return nil
+	return withFileLock(s.path, func() error {
+		scores, err := s.Load()
+		if err != nil {
+			return err
+		}
+		scores = mergeScore(scores, entry)
+
+		return writeFileAtomic(s.path, func(w io.Writer) error {
+			for _, e := range scores {
+				line := e.Name + "|" + strconv.Itoa(e.Level) + "|" + strconv.Itoa(e.Score) + "|" + e.Skill.String() + "|" + e.Fingerprint + "|" + strconv.Itoa(e.Undos) + "\n"
+				if _, err := io.WriteString(w, line); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
 }
 
-func LoadScores() ([]ScoreEntry, error) {
-	f, err := os.Open(scoresFile)
-	if err != nil {
+func (s *FileScoreStore) Load() ([]ScoreEntry, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
 		return []ScoreEntry{}, nil
 	}
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
 	var scores []ScoreEntry
@@ -67,28 +120,63 @@ func LoadScores() ([]ScoreEntry, error) {
 
 	for scanner.Scan() {
 		parts := strings.Split(scanner.Text(), "|")
-		if len(parts) == 3 {
+		if len(parts) >= 3 {
 			level, _ := strconv.Atoi(parts[1])
 			score, _ := strconv.Atoi(parts[2])
+			// Scores saved before skill levels existed were played on Standard.
+			skill := SkillStandard
+			if len(parts) >= 4 {
+				skill, _ = ParseSkill(parts[3])
+			}
+			fingerprint := ""
+			if len(parts) >= 5 {
+				fingerprint = parts[4]
+			}
+			undos := 0
+			if len(parts) >= 6 {
+				undos, _ = strconv.Atoi(parts[5])
+			}
 			scores = append(scores, ScoreEntry{
-				Name:  parts[0],
-				Level: level,
-				Score: score,
+				Name:        parts[0],
+				Level:       level,
+				Score:       score,
+				Skill:       skill,
+				Fingerprint: fingerprint,
+				Undos:       undos,
 			})
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].Score > scores[j].Score
-	})
+	sortScores(scores)
 
 	return scores, nil
 }
 
-func GetTopScores(n int) []ScoreEntry {
-	scores, _ := LoadScores()
-	if len(scores) > n {
-		return scores[:n]
-	}
-	return scores
+type MemoryScoreStore struct {
+	mu     sync.Mutex
+	scores []ScoreEntry
+}
+
+func NewMemoryScoreStore() *MemoryScoreStore {
+	return &MemoryScoreStore{}
+}
+
+func (s *MemoryScoreStore) Save(entry ScoreEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scores = mergeScore(s.scores, entry)
+	return nil
+}
+
+func (s *MemoryScoreStore) Load() ([]ScoreEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scores := make([]ScoreEntry, len(s.scores))
+	copy(scores, s.scores)
+	return scores, nil
 }
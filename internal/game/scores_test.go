@@ -0,0 +1,83 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestFileScoreStoreConcurrentSaves saves from many goroutines, each through
+// its own store as separate sessions' processes would, while a reader checks
+// the file is never half written.
+func TestFileScoreStoreConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.txt")
+	const players = 2 * maxScores
+
+	done := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(readErr)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(lines) > maxScores {
+				readErr <- fmt.Errorf("file has %d lines, want at most %d", len(lines), maxScores)
+				return
+			}
+			for _, line := range lines {
+				if len(strings.Split(line, "|")) != 6 {
+					readErr <- fmt.Errorf("malformed line %q", line)
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := range players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := ScoreEntry{Name: fmt.Sprintf("player%02d", i), Level: 1, Score: i * 10, Skill: SkillStandard}
+			if err := NewFileScoreStore(path).Save(entry); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	if err := <-readErr; err != nil {
+		t.Fatal(err)
+	}
+
+	scores, err := NewFileScoreStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != maxScores {
+		t.Fatalf("%d scores kept, want %d", len(scores), maxScores)
+	}
+	for rank, s := range scores {
+		i := players - 1 - rank
+		if want := fmt.Sprintf("player%02d", i); s.Name != want || s.Score != i*10 {
+			t.Errorf("rank %d = %s %d, want %s %d", rank+1, s.Name, s.Score, want, i*10)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func countType(g *Game, t EntityType) int {
+	n := 0
+	for _, e := range g.Entities {
+		if e.Type == t {
+			n++
+		}
+	}
+	return n
+}
+
+func TestShrubsSpawn(t *testing.T) {
+	for _, skill := range Skills {
+		d := skill.Difficulty()
+		g := NewWithSeed(60, 30, d, 7)
+		for level := 1; level <= 3; level++ {
+			_, _, _, want, _ := d.levelCounts(level)
+			if got := countType(g, EntityShrub); got != want {
+				t.Errorf("%v level %d: %d shrubs, want %d", skill, level, got, want)
+			}
+			g.NextLevel()
+		}
+	}
+}
+
+func TestRobotWalksIntoShrub(t *testing.T) {
+	for _, tt := range []struct {
+		chance   int
+		exploded bool
+		want     []Entity
+	}{
+		{chance: 0, exploded: false, want: []Entity{robot(2, 0)}},
+		{chance: 100, exploded: true, want: []Entity{junk(2, 0)}},
+	} {
+		g := newTestGame(robot(3, 0), shrub(2, 0))
+		g.Difficulty.ShrubExplodeChance = tt.chance
+		var hits []RobotHitShrub
+		g.Subscribe(func(e Event) {
+			if hit, ok := e.(RobotHitShrub); ok {
+				hits = append(hits, hit)
+			}
+		})
+
+		g.stepRobots(false)
+
+		if got := sortedEntities(g); !slices.Equal(got, tt.want) {
+			t.Errorf("chance %d: entities = %v, want %v", tt.chance, got, tt.want)
+		}
+		if want := []RobotHitShrub{{Pos: Position{X: 2, Y: 0}, Exploded: tt.exploded}}; !slices.Equal(hits, want) {
+			t.Errorf("chance %d: events = %v, want %v", tt.chance, hits, want)
+		}
+	}
+}
+
+func TestRobotsPileIntoShrub(t *testing.T) {
+	// Two robots closing in on the player from either side meet in the
+	// shrub, which always explodes a lone robot here.
+	g := newTestGame(robot(3, 2), robot(5, 2), shrub(4, 3))
+	g.Player = Position{X: 4, Y: 8}
+	g.Difficulty.ShrubExplodeChance = 100
+
+	g.stepRobots(false)
+
+	if got, want := sortedEntities(g), []Entity{junk(4, 3)}; !slices.Equal(got, want) {
+		t.Fatalf("entities = %v, want %v", got, want)
+	}
+}
+
+func TestPlayerBumpsShrub(t *testing.T) {
+	g := newTestGame(shrub(1, 0), robot(19, 19))
+
+	for bump := 1; bump <= maxHealth; bump++ {
+		g.MovePlayer(1, 0)
+
+		if g.Player != (Position{}) {
+			t.Fatalf("bump %d: player moved to %v", bump, g.Player)
+		}
+		if want := maxHealth - bump; g.Health != want {
+			t.Fatalf("bump %d: health = %d, want %d", bump, g.Health, want)
+		}
+		if want := -bump * shrubPenalty; g.Score != want {
+			t.Fatalf("bump %d: score = %d, want %d", bump, g.Score, want)
+		}
+		if dead := bump == maxHealth; g.GameOver != dead {
+			t.Fatalf("bump %d: game over = %v, want %v", bump, g.GameOver, dead)
+		}
+	}
+	if g.DeathCause != CauseShrubs {
+		t.Fatalf("death cause = %v, want %v", g.DeathCause, CauseShrubs)
+	}
+	if countType(g, EntityShrub) != 1 {
+		t.Fatal("bumping the shrub removed it")
+	}
+}
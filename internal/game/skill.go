@@ -0,0 +1,185 @@
+package game
+
+import "strings"
+
+type Skill int
+
+const (
+	SkillNovice Skill = iota
+	SkillStandard
+	SkillVeteran
+	SkillNightmare
+)
+
+var Skills = []Skill{SkillNovice, SkillStandard, SkillVeteran, SkillNightmare}
+
+func (s Skill) String() string {
+	switch s {
+	case SkillNovice:
+		return "Novice"
+	case SkillStandard:
+		return "Standard"
+	case SkillVeteran:
+		return "Veteran"
+	case SkillNightmare:
+		return "Nightmare"
+	default:
+		return "Unknown"
+	}
+}
+
+func ParseSkill(name string) (Skill, bool) {
+	for _, s := range Skills {
+		if strings.EqualFold(s.String(), name) {
+			return s, true
+		}
+	}
+	return SkillStandard, false
+}
+
+func (s Skill) Description() string {
+	switch s {
+	case SkillNovice:
+		return "Fewer, slower-growing robots, plenty of tools and fragile shrubs"
+	case SkillStandard:
+		return "The classic arena"
+	case SkillVeteran:
+		return "Crowded arenas, robots spawn close and tools run dry"
+	case SkillNightmare:
+		return "Robots everywhere, sturdy shrubs and almost nothing to help you"
+	default:
+		return ""
+	}
+}
+
+func (s Skill) Difficulty() Difficulty {
+	switch s {
+	case SkillNovice:
+		return Difficulty{
+			Skill:              s,
+			RobotCount:         6,
+			ObstacleCount:      10,
+			ShrubCount:         10,
+			MinSpawnDist:       7,
+			RobotGrowth:        1,
+			ObstacleGrowth:     2,
+			ShrubGrowth:        1,
+			MinSpawnDistFloor:  5,
+			ShrubExplodeChance: 50,
+			DiagonalMoves:      true,
+			UndoBudget:         5,
+			FastRobotLevel:     6,
+			FastRobotGrowth:    1,
+			Teleports:          7,
+			EMPs:               4,
+			Blasters:           3,
+			TeleportRefill:     6,
+			EMPRefill:          4,
+			BlasterRefill:      2,
+			SafeTeleports:      3,
+			SafeTeleportRefill: 1,
+			PickupEvery:        12,
+			PickupTTL:          15,
+			RobotMix: []RobotMix{
+				{Kind: RobotCautious, FromLevel: 4, Percent: 20},
+				{Kind: RobotPathfinder, FromLevel: 6, Percent: 10},
+				{Kind: RobotFlanker, FromLevel: 8, Percent: 10},
+			},
+			Blaster: Weapon{Name: "Shock ring", Shape: ShapeRing, Size: 2},
+		}
+	case SkillVeteran:
+		return Difficulty{
+			Skill:              s,
+			RobotCount:         14,
+			ObstacleCount:      18,
+			ShrubCount:         6,
+			MinSpawnDist:       4,
+			RobotGrowth:        3,
+			ObstacleGrowth:     3,
+			ShrubGrowth:        1,
+			MinSpawnDistFloor:  2,
+			ShrubExplodeChance: 20,
+			DiagonalMoves:      true,
+			UndoBudget:         1,
+			FastRobotLevel:     3,
+			FastRobotGrowth:    1,
+			Teleports:          4,
+			EMPs:               2,
+			Blasters:           1,
+			TeleportRefill:     3,
+			EMPRefill:          2,
+			BlasterRefill:      1,
+			SafeTeleports:      1,
+			SafeTeleportRefill: 1,
+			PickupEvery:        20,
+			PickupTTL:          10,
+			RobotMix: []RobotMix{
+				{Kind: RobotCautious, FromLevel: 2, Percent: 20},
+				{Kind: RobotFlanker, FromLevel: 3, Percent: 20},
+				{Kind: RobotPathfinder, FromLevel: 5, Percent: 20},
+			},
+			Blaster: Weapon{Name: "Cross blaster", Shape: ShapeCross, Size: 2, Range: 8},
+		}
+	case SkillNightmare:
+		return Difficulty{
+			Skill:              s,
+			RobotCount:         18,
+			ObstacleCount:      20,
+			ShrubCount:         4,
+			MinSpawnDist:       3,
+			RobotGrowth:        4,
+			ObstacleGrowth:     4,
+			MinSpawnDistFloor:  2,
+			ShrubExplodeChance: 10,
+			FastRobotLevel:     2,
+			FastRobotGrowth:    2,
+			Teleports:          3,
+			EMPs:               1,
+			Blasters:           1,
+			TeleportRefill:     2,
+			EMPRefill:          1,
+			SafeTeleports:      1,
+			PickupEvery:        25,
+			PickupTTL:          8,
+			RobotMix: []RobotMix{
+				{Kind: RobotPathfinder, FromLevel: 1, Percent: 20},
+				{Kind: RobotFlanker, FromLevel: 2, Percent: 20},
+				{Kind: RobotCautious, FromLevel: 3, Percent: 20},
+			},
+			Blaster: Weapon{Name: "Rail gun", Shape: ShapeLine, Size: 3, Range: 6, LeavesJunk: true},
+		}
+	default:
+		return Difficulty{
+			Skill:              SkillStandard,
+			RobotCount:         10,
+			ObstacleCount:      15,
+			ShrubCount:         8,
+			MinSpawnDist:       5,
+			RobotGrowth:        2,
+			ObstacleGrowth:     3,
+			ShrubGrowth:        1,
+			MinSpawnDistFloor:  3,
+			ShrubExplodeChance: 30,
+			DiagonalMoves:      true,
+			UndoBudget:         2,
+			FastRobotLevel:     4,
+			FastRobotGrowth:    1,
+			Teleports:          5,
+			EMPs:               3,
+			Blasters:           2,
+			TeleportRefill:     5,
+			EMPRefill:          3,
+			BlasterRefill:      1,
+			SafeTeleports:      2,
+			SafeTeleportRefill: 1,
+			PickupEvery:        15,
+			PickupTTL:          12,
+			RobotMix: []RobotMix{
+				{Kind: RobotCautious, FromLevel: 3, Percent: 20},
+				{Kind: RobotFlanker, FromLevel: 5, Percent: 15},
+				{Kind: RobotPathfinder, FromLevel: 7, Percent: 15},
+			},
+			Blaster: ClassicBlaster,
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package game
+
+// occupancy indexes g.Entities by cell so lookups do not scan every entity.
+// Each cell holds a linked list of entity indices, since robots can share a
+// cell between moving and CheckCollisions.
+type occupancy struct {
+	width int
+	head  []int // first entity in each cell, -1 when empty
+	next  []int // next entity in the same cell, -1 at the end
+
+	// base and n identify the slice the index was built from, so assigning
+	// g.Entities a new slice is noticed and the index rebuilt.
+	base *Entity
+	n    int
+}
+
+// index returns the occupancy index, rebuilding it if g.Entities has been
+// replaced since it was built. Entities moved in place must go through
+// moveEntity to keep it in sync.
+func (g *Game) index() *occupancy {
+	o := g.occ
+	if o != nil && o.width == g.Width && len(o.head) == g.Width*g.Height && o.n == len(g.Entities) && o.base == firstEntity(g.Entities) {
+		return o
+	}
+
+	if o == nil {
+		o = &occupancy{}
+		g.occ = o
+	}
+	o.width = g.Width
+	o.base = firstEntity(g.Entities)
+	o.n = len(g.Entities)
+
+	cells := g.Width * g.Height
+	if cap(o.head) < cells {
+		o.head = make([]int, cells)
+	}
+	o.head = o.head[:cells]
+	for i := range o.head {
+		o.head[i] = -1
+	}
+	if cap(o.next) < len(g.Entities) {
+		o.next = make([]int, len(g.Entities))
+	}
+	o.next = o.next[:len(g.Entities)]
+
+	// Link from the back so each cell lists its entities in slice order.
+	for i := len(g.Entities) - 1; i >= 0; i-- {
+		o.next[i] = -1
+		if c := o.cell(g.Entities[i].Pos); c >= 0 {
+			o.next[i] = o.head[c]
+			o.head[c] = i
+		}
+	}
+	return o
+}
+
+func firstEntity(entities []Entity) *Entity {
+	if len(entities) == 0 {
+		return nil
+	}
+	return &entities[0]
+}
+
+func (o *occupancy) cell(p Position) int {
+	if p.X < 0 || p.X >= o.width || p.Y < 0 || p.Y*o.width >= len(o.head) {
+		return -1
+	}
+	return p.Y*o.width + p.X
+}
+
+func (o *occupancy) unlink(i int, p Position) {
+	c := o.cell(p)
+	if c < 0 {
+		return
+	}
+	if o.head[c] == i {
+		o.head[c] = o.next[i]
+		return
+	}
+	for j := o.head[c]; j >= 0; j = o.next[j] {
+		if o.next[j] == i {
+			o.next[j] = o.next[i]
+			return
+		}
+	}
+}
+
+func (o *occupancy) link(i int, p Position) {
+	c := o.cell(p)
+	if c < 0 {
+		o.next[i] = -1
+		return
+	}
+	// Keep the list in slice order so lookups match a linear scan.
+	if o.head[c] < 0 || o.head[c] > i {
+		o.next[i] = o.head[c]
+		o.head[c] = i
+		return
+	}
+	j := o.head[c]
+	for o.next[j] >= 0 && o.next[j] < i {
+		j = o.next[j]
+	}
+	o.next[i] = o.next[j]
+	o.next[j] = i
+}
+
+// moveEntity moves entity i to p and updates the index.
+func (g *Game) moveEntity(i int, p Position) {
+	o := g.index()
+	o.unlink(i, g.Entities[i].Pos)
+	g.Entities[i].Pos = p
+	o.link(i, p)
+}
+
+// firstAt returns the index of the first entity at p that match accepts, or
+// -1 if there is none. A nil match accepts anything.
+func (g *Game) firstAt(p Position, match func(i int) bool) int {
+	o := g.index()
+	c := o.cell(p)
+	if c < 0 {
+		return -1
+	}
+	for i := o.head[c]; i >= 0; i = o.next[i] {
+		if match == nil || match(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendAt appends the indices of the entities at p to dst in slice order.
+func (g *Game) appendAt(dst []int, p Position) []int {
+	o := g.index()
+	c := o.cell(p)
+	if c < 0 {
+		return dst
+	}
+	for i := o.head[c]; i >= 0; i = o.next[i] {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+func (g *Game) occupied(p Position) bool {
+	return g.firstAt(p, nil) >= 0
+}
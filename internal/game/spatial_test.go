@@ -0,0 +1,148 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkIndex compares every lookup the occupancy index answers with a scan
+// of g.Entities.
+func checkIndex(t *testing.T, g *Game, when string) {
+	t.Helper()
+	for y := -1; y <= g.Height; y++ {
+		for x := -1; x <= g.Width; x++ {
+			p := Position{X: x, Y: y}
+			var want []int
+			for i, e := range g.Entities {
+				if e.Pos == p {
+					want = append(want, i)
+				}
+			}
+			if got := g.appendAt(nil, p); !slices.Equal(got, want) {
+				t.Fatalf("%s: appendAt(%v) = %v, want %v", when, p, got, want)
+			}
+
+			first := -1
+			if len(want) > 0 {
+				first = want[0]
+			}
+			if got := g.firstAt(p, nil); got != first {
+				t.Fatalf("%s: firstAt(%v) = %d, want %d", when, p, got, first)
+			}
+			for _, typ := range []EntityType{EntityRobot, EntityJunk, EntityObstacle, EntityShrub, EntityPickup} {
+				first := -1
+				for _, i := range want {
+					if g.Entities[i].Type == typ {
+						first = i
+						break
+					}
+				}
+				if got := g.firstAt(p, func(i int) bool { return g.Entities[i].Type == typ }); got != first {
+					t.Fatalf("%s: firstAt(%v, type %d) = %d, want %d", when, p, typ, got, first)
+				}
+			}
+		}
+	}
+}
+
+func TestOccupancyMatchesScan(t *testing.T) {
+	for seed := range uint64(10) {
+		g := NewWithSeed(40, 20, SkillVeteran.Difficulty(), seed)
+		for range 4 {
+			g.NextLevel()
+		}
+		g.UndosLeft = 5
+		checkIndex(t, g, "start")
+
+		undos := 0
+	turns:
+		for turn := range 60 {
+			switch {
+			case g.GameOver:
+				if !g.Undo() {
+					break turns
+				}
+				undos++
+			case turn%7 == 3:
+				g.Teleports = max(g.Teleports, 1)
+				g.Teleport()
+			case turn%5 == 4 && g.Undo():
+				undos++
+			default:
+				g.MovePlayer([]int{-1, 0, 1}[turn%3], []int{1, 0, -1}[turn%2])
+			}
+			checkIndex(t, g, "after a turn")
+			g.UndosLeft = max(g.UndosLeft, 1)
+		}
+		if undos == 0 {
+			t.Errorf("seed %d: no turn was taken back", seed)
+		}
+	}
+}
+
+// benchGame returns a big arena at the given level of a crowded skill, as a
+// long run on a wide terminal would reach.
+func benchGame(level int) *Game {
+	g := NewWithSeed(200, 60, SkillVeteran.Difficulty(), 1)
+	g.Level = level - 1
+	g.NextLevel()
+	return g
+}
+
+func BenchmarkTurn(b *testing.B) {
+	g := benchGame(50)
+	for range b.N {
+		if g.GameOver || g.Level != 50 {
+			b.StopTimer()
+			g = benchGame(50)
+			b.StartTimer()
+		}
+		g.Wait()
+	}
+}
+
+func BenchmarkSpawn(b *testing.B) {
+	g := benchGame(50)
+	for range b.N {
+		g.spawnEntities(g.Difficulty.levelCounts(50))
+	}
+}
+
+func BenchmarkTeleport(b *testing.B) {
+	g := benchGame(50)
+	for range b.N {
+		g.Teleports = 1
+		g.Teleport()
+	}
+}
+
+// BenchmarkLookup compares a sweep of every cell through the index with the
+// linear scan of g.Entities it replaced.
+func BenchmarkLookup(b *testing.B) {
+	g := benchGame(50)
+	isRobot := func(i int) bool { return g.Entities[i].Type == EntityRobot }
+
+	b.Run("index", func(b *testing.B) {
+		for range b.N {
+			for y := range g.Height {
+				for x := range g.Width {
+					g.firstAt(Position{X: x, Y: y}, isRobot)
+				}
+			}
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for range b.N {
+			for y := range g.Height {
+				for x := range g.Width {
+					p := Position{X: x, Y: y}
+					for i, e := range g.Entities {
+						if e.Pos == p && isRobot(i) {
+							break
+						}
+					}
+				}
+			}
+		}
+	})
+}
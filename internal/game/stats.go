@@ -0,0 +1,63 @@
+package game
+
+type LevelStats struct {
+	Level           int
+	Teleports       int
+	EMPs            int
+	Blasters        int
+	Pickups         int
+	RobotsDestroyed int
+}
+
+// Stats summarizes a run for the post-mortem. The engine keeps it up to date
+// from its own event stream.
+type Stats struct {
+	RobotsDestroyed int
+	BestKillChain   int
+	Levels          []LevelStats
+}
+
+func (s *Stats) level(level int) *LevelStats {
+	for len(s.Levels) < level {
+		s.Levels = append(s.Levels, LevelStats{Level: len(s.Levels) + 1})
+	}
+	return &s.Levels[level-1]
+}
+
+func (g *Game) recordStats(e Event) {
+	level := g.Stats.level(g.Level)
+
+	destroyed := 0
+	switch e := e.(type) {
+	case RobotsCollided:
+		destroyed = e.Count
+	case RobotsBlasted:
+		destroyed = e.Count
+	case ShieldHit:
+		destroyed = e.Count
+	case PickupCollected:
+		level.Pickups++
+	case RobotHitJunk:
+		destroyed = 1
+	case RobotHitShrub:
+		if e.Exploded {
+			destroyed = 1
+		}
+	case ToolUsed:
+		switch e.Tool {
+		case ToolTeleport, ToolSafeTeleport, ToolBlink:
+			level.Teleports++
+		case ToolEMP:
+			level.EMPs++
+		case ToolBlaster:
+			level.Blasters++
+		}
+	case ScoreChanged:
+		if e.Reason == ScoreKill {
+			g.Stats.BestKillChain = max(g.Stats.BestKillChain, g.ConsecutiveKills)
+		}
+	}
+
+	level.RobotsDestroyed += destroyed
+	g.Stats.RobotsDestroyed += destroyed
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// snapshot is the whole game as it stood before a turn, including the random
+// source so the turn replays differently only if the player plays it
+// differently.
+type snapshot struct {
+	game Game
+	src  rand.PCG
+}
+
+// checkpoint saves the state before a turn. Only as many turns as the player
+// can still take back are kept.
+func (g *Game) checkpoint() {
+	if g.UndosLeft <= 0 {
+		return
+	}
+
+	s := snapshot{game: *g, src: *g.src}
+	s.game.Entities = slices.Clone(g.Entities)
+	s.game.Stats.Levels = slices.Clone(g.Stats.Levels)
+	s.game.actions = nil
+	s.game.subscribers = nil
+	s.game.history = nil
+	s.game.occ = nil
+
+	g.history = append(g.history, s)
+	if len(g.history) > g.UndosLeft {
+		g.history = g.history[len(g.history)-g.UndosLeft:]
+	}
+}
+
+func (g *Game) CanUndo() bool {
+	return g.UndosLeft > 0 && len(g.history) > 0
+}
+
+// Undo takes back the last turn, even the one that killed the player, and
+// charges the undo penalty. Undos already charged before the restored turn
+// are part of its score, so only the ones since are charged again.
+func (g *Game) Undo() bool {
+	if !g.CanUndo() {
+		return false
+	}
+	g.record(Action{Kind: ActionUndo})
+
+	s := g.history[len(g.history)-1]
+	history := g.history[:len(g.history)-1]
+	actions, subscribers, src, occ := g.actions, g.subscribers, g.src, g.occ
+	undosLeft, undosUsed := g.UndosLeft-1, g.UndosUsed+1
+
+	*g = s.game
+	*src = s.src
+	g.src = src
+	g.actions = actions
+	g.subscribers = subscribers
+	g.history = history
+	g.occ = occ
+	g.UndosLeft = undosLeft
+	g.UndosUsed = undosUsed
+
+	g.addScore(-(g.UndosUsed-s.game.UndosUsed)*undoPenalty, ScoreUndo)
+	return true
+}
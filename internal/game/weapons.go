@@ -0,0 +1,74 @@
+package game
+
+type BlastShape int
+
+const (
+	ShapeSquare BlastShape = iota
+	ShapeCross
+	ShapeLine
+	ShapeRing
+)
+
+func (s BlastShape) String() string {
+	switch s {
+	case ShapeSquare:
+		return "square"
+	case ShapeCross:
+		return "cross"
+	case ShapeLine:
+		return "line"
+	case ShapeRing:
+		return "ring"
+	default:
+		return "unknown"
+	}
+}
+
+// Weapon describes what a blaster shot hits. Size is how far the blast
+// reaches from its target: the square's and ring's radius, the cross's arm
+// and half the line. Range caps how far from the player the target may be,
+// zero for anywhere in the arena.
+type Weapon struct {
+	Name       string
+	Shape      BlastShape
+	Size       int
+	Range      int
+	LeavesJunk bool
+}
+
+// ClassicBlaster is the 3x3 blaster the game has always had.
+var ClassicBlaster = Weapon{Name: "Blaster", Shape: ShapeSquare, Size: 1}
+
+// Weapon is the blaster a new game starts with. Difficulties saved before
+// weapons existed get the classic one.
+func (d Difficulty) Weapon() Weapon {
+	if d.Blaster == (Weapon{}) {
+		return ClassicBlaster
+	}
+	return d.Blaster
+}
+
+// hits reports whether a shot from a player at from, aimed at target, hits
+// p. A line lies along whichever axis the target is further from the player
+// on.
+func (w Weapon) hits(from, target, p Position) bool {
+	dx, dy := abs(p.X-target.X), abs(p.Y-target.Y)
+	switch w.Shape {
+	case ShapeCross:
+		return (dx == 0 && dy <= w.Size) || (dy == 0 && dx <= w.Size)
+	case ShapeLine:
+		if abs(target.X-from.X) >= abs(target.Y-from.Y) {
+			return dy == 0 && dx <= w.Size
+		}
+		return dx == 0 && dy <= w.Size
+	case ShapeRing:
+		return max(dx, dy) == w.Size
+	default:
+		return max(dx, dy) <= w.Size
+	}
+}
+
+// inRange reports whether target may be aimed at from from.
+func (w Weapon) inRange(from, target Position) bool {
+	return w.Range == 0 || chebyshev(from, target) <= w.Range
+}
@@ -0,0 +1,63 @@
+package game
+
+import "testing"
+
+func TestWeaponHits(t *testing.T) {
+	at := func(x, y int) Position { return Position{X: x, Y: y} }
+	player, target := at(0, 5), at(5, 5)
+
+	tests := []struct {
+		name   string
+		weapon Weapon
+		p      Position
+		want   bool
+	}{
+		{"square centre", Weapon{Shape: ShapeSquare, Size: 1}, at(5, 5), true},
+		{"square corner", Weapon{Shape: ShapeSquare, Size: 1}, at(6, 6), true},
+		{"square outside", Weapon{Shape: ShapeSquare, Size: 1}, at(7, 5), false},
+		{"cross arm", Weapon{Shape: ShapeCross, Size: 2}, at(5, 7), true},
+		{"cross arm end", Weapon{Shape: ShapeCross, Size: 2}, at(3, 5), true},
+		{"cross diagonal", Weapon{Shape: ShapeCross, Size: 2}, at(6, 6), false},
+		{"cross past the arm", Weapon{Shape: ShapeCross, Size: 2}, at(8, 5), false},
+		{"line along the aim", Weapon{Shape: ShapeLine, Size: 3}, at(8, 5), true},
+		{"line past its end", Weapon{Shape: ShapeLine, Size: 3}, at(9, 5), false},
+		{"line across the aim", Weapon{Shape: ShapeLine, Size: 3}, at(5, 6), false},
+		{"ring edge", Weapon{Shape: ShapeRing, Size: 2}, at(7, 3), true},
+		{"ring side", Weapon{Shape: ShapeRing, Size: 2}, at(5, 7), true},
+		{"ring centre", Weapon{Shape: ShapeRing, Size: 2}, at(5, 5), false},
+		{"ring inside", Weapon{Shape: ShapeRing, Size: 2}, at(6, 4), false},
+		{"ring outside", Weapon{Shape: ShapeRing, Size: 2}, at(8, 5), false},
+	}
+	for _, tt := range tests {
+		if got := tt.weapon.hits(player, target, tt.p); got != tt.want {
+			t.Errorf("%s: hits(%v) = %v, want %v", tt.name, tt.p, got, tt.want)
+		}
+	}
+
+	// A line turns to lie along whichever axis the target is further away on.
+	line := Weapon{Shape: ShapeLine, Size: 1}
+	if !line.hits(at(5, 0), at(5, 5), at(5, 6)) || line.hits(at(5, 0), at(5, 5), at(6, 5)) {
+		t.Error("a line aimed down the column should lie along it")
+	}
+}
+
+func TestWeaponInRange(t *testing.T) {
+	from := Position{X: 10, Y: 10}
+	for _, shape := range []BlastShape{ShapeSquare, ShapeCross, ShapeLine, ShapeRing} {
+		for _, tt := range []struct {
+			rng    int
+			target Position
+			want   bool
+		}{
+			{0, Position{X: 39, Y: 0}, true},
+			{4, Position{X: 14, Y: 6}, true},
+			{4, Position{X: 15, Y: 10}, false},
+			{4, Position{X: 10, Y: 5}, false},
+		} {
+			w := Weapon{Shape: shape, Size: 1, Range: tt.rng}
+			if got := w.inRange(from, tt.target); got != tt.want {
+				t.Errorf("%v range %d: inRange(%v) = %v, want %v", shape, tt.rng, tt.target, got, tt.want)
+			}
+		}
+	}
+}
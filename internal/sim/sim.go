@@ -0,0 +1,107 @@
+package sim
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/ayehia0/deathmatch/internal/game"
+)
+
+// Strategy decides a bot's next action. The rng is seeded per game so every
+// simulated run is reproducible from its seed.
+type Strategy interface {
+	Next(g *game.Game, rng *rand.Rand) game.Action
+}
+
+var Strategies = map[string]Strategy{
+	"random":  Random{},
+	"evasive": Evasive{},
+}
+
+func StrategyNames() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type Result struct {
+	Seed     uint64 `json:"seed"`
+	Strategy string `json:"strategy"`
+	Skill    string `json:"skill"`
+	Level    int    `json:"level"`
+	Score    int    `json:"score"`
+	Turns    int    `json:"turns"`
+	Cause    string `json:"cause"`
+}
+
+type Config struct {
+	Width      int
+	Height     int
+	Difficulty game.Difficulty
+	MaxTurns   int
+	Workers    int
+}
+
+func Run(cfg Config, name string, strategy Strategy, seed uint64) Result {
+	g := game.NewWithSeed(cfg.Width, cfg.Height, cfg.Difficulty, seed)
+	rng := rand.New(rand.NewPCG(seed, 0))
+
+	cause := "survived"
+	g.Subscribe(func(e game.Event) {
+		if killed, ok := e.(game.PlayerKilled); ok {
+			cause = killed.Cause.String()
+		}
+	})
+
+	// Not every action advances a turn (a failed teleport, walking into the
+	// arena edge), so cap the actions as well to stop a stuck bot.
+	for actions := 0; !g.GameOver && g.Turns < cfg.MaxTurns && actions < cfg.MaxTurns*4; actions++ {
+		strategy.Next(g, rng).Apply(g)
+	}
+
+	return Result{
+		Seed:     seed,
+		Strategy: name,
+		Skill:    cfg.Difficulty.Skill.String(),
+		Level:    g.Level,
+		Score:    g.Score,
+		Turns:    g.Turns,
+		Cause:    cause,
+	}
+}
+
+// RunMany plays one game per seed on a pool of workers and returns the
+// results in seed order.
+func RunMany(cfg Config, name string, strategy Strategy, seeds []uint64) []Result {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]Result, len(seeds))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = Run(cfg, name, strategy, seeds[i])
+			}
+		}()
+	}
+
+	for i := range seeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
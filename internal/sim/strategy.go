@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"math/rand/v2"
+
+	"github.com/ayehia0/deathmatch/internal/game"
+)
+
+var (
+	straightMoves = []game.Position{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+	allMoves      = append([]game.Position{{X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: 1, Y: 1}}, straightMoves...)
+)
+
+func moves(g *game.Game) []game.Position {
+	if g.Difficulty.DiagonalMoves {
+		return allMoves
+	}
+	return straightMoves
+}
+
+// Random mashes the movement keys.
+type Random struct{}
+
+func (Random) Next(g *game.Game, rng *rand.Rand) game.Action {
+	options := moves(g)
+	m := options[rng.IntN(len(options))]
+	return game.Action{Kind: game.ActionMove, DX: m.X, DY: m.Y}
+}
+
+// Evasive takes the move that keeps it furthest from the robots without
+// stepping next to one, and falls back on safe teleports, teleports and EMP
+// pulses when cornered.
+type Evasive struct{}
+
+func (Evasive) Next(g *game.Game, rng *rand.Rand) game.Action {
+	occupied := make(map[game.Position]bool, len(g.Entities))
+	// Stunned robots will not move next turn, so only the others are a
+	// threat.
+	var robots []game.Position
+	for _, e := range g.Entities {
+		if e.Type != game.EntityPickup {
+			occupied[e.Pos] = true
+		}
+		if e.Type == game.EntityRobot && e.Stunned == 0 {
+			robots = append(robots, e.Pos)
+		}
+	}
+
+	best := -1
+	var bestMove game.Position
+	for _, m := range moves(g) {
+		pos := game.Position{X: g.Player.X + m.X, Y: g.Player.Y + m.Y}
+		if pos.X < 0 || pos.X >= g.Width || pos.Y < 0 || pos.Y >= g.Height || occupied[pos] {
+			continue
+		}
+
+		dist := nearest(pos, robots)
+		if dist <= 1 {
+			continue
+		}
+		// Break ties randomly so the bot does not oscillate.
+		score := dist*4 + rng.IntN(4)
+		if score > best {
+			best = score
+			bestMove = m
+		}
+	}
+
+	if best >= 0 {
+		return game.Action{Kind: game.ActionMove, DX: bestMove.X, DY: bestMove.Y}
+	}
+	if g.SafeTeleports > 0 {
+		return game.Action{Kind: game.ActionSafeTeleport}
+	}
+	if g.Teleports > 0 {
+		return game.Action{Kind: game.ActionTeleport}
+	}
+	if g.EMPs > 0 {
+		return game.Action{Kind: game.ActionEMPPulse}
+	}
+	return Random{}.Next(g, rng)
+}
+
+func nearest(pos game.Position, robots []game.Position) int {
+	best := 1 << 30
+	for _, r := range robots {
+		d := max(abs(pos.X-r.X), abs(pos.Y-r.Y))
+		best = min(best, d)
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
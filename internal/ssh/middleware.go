@@ -1,25 +1,153 @@
 package ssh
 
 import (
+	"encoding/base64"
+	"log"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/ayehia0/deathmatch/internal/game"
 	"github.com/ayehia0/deathmatch/internal/ui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
 )
 
-func TeaHandler() bubbletea.Handler {
+type sessionKey struct{}
+
+func TeaHandler(opts ui.Options, accounts game.AccountStore) bubbletea.Handler {
 	return func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 		username := s.User()
+		if !validName(username) {
+			wish.Fatalln(s, badNameMessage)
+			return nil, nil
+		}
 		if username == "" {
 			username = "Player"
 		}
 
+		fingerprint := Fingerprint(s)
+		// Scores are kept by name, so playing on under a stand-in name
+		// would put them on that name's owner's entry.
+		name, err := accounts.Claim(username, fingerprint)
+		if err != nil {
+			log.Printf("claiming name %q: %v", username, err)
+			wish.Fatalln(s, "Could not sign you in as "+username+", please try again later.")
+			return nil, nil
+		}
+
 		renderer := bubbletea.MakeRenderer(s)
 		renderer.SetColorProfile(termenv.TrueColor)
 
-		return ui.NewModelWithName(username), []tea.ProgramOption{
+		model := ui.NewModelWithName(name, fingerprint, opts)
+		s.Context().SetValue(sessionKey{}, model.Session())
+
+		return model, []tea.ProgramOption{
 			tea.WithAltScreen(),
 		}
 	}
 }
+
+// SaveOnExit saves the game a session was playing when it ends, whether the
+// player quit or the connection dropped. It must come before the bubbletea
+// middleware so it runs once the program has exited.
+func SaveOnExit() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if session, ok := s.Context().Value(sessionKey{}).(*ui.Session); ok {
+				if err := session.Save(); err != nil {
+					log.Printf("saving game for %s: %v", s.User(), err)
+				}
+			}
+			next(s)
+		}
+	}
+}
+
+// CommandMiddleware answers non-interactive account commands before the game
+// starts:
+//
+//	ssh alice@host whoami              prints the key's fingerprint
+//	ssh alice@host link <fingerprint>  links another key to alice
+func CommandMiddleware(accounts game.AccountStore) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			cmd := s.Command()
+			if len(cmd) == 0 {
+				next(s)
+				return
+			}
+
+			switch {
+			case cmd[0] == "whoami" && len(cmd) == 1:
+				wish.Println(s, Fingerprint(s))
+			case cmd[0] == "link" && len(cmd) == 2:
+				name := s.User()
+				if !validName(name) {
+					wish.Fatalln(s, badNameMessage)
+					return
+				}
+				if !validFingerprint(cmd[1]) {
+					wish.Fatalln(s, "link failed: "+cmd[1]+" is not a SHA256 key fingerprint")
+					return
+				}
+				if err := accounts.Link(name, Fingerprint(s), cmd[1]); err != nil {
+					wish.Fatalln(s, "link failed: "+err.Error())
+					return
+				}
+				wish.Println(s, "linked "+cmd[1]+" to "+name)
+			default:
+				wish.Fatalln(s, "usage: whoami | link <fingerprint>")
+			}
+		}
+	}
+}
+
+// LimitSessions turns away new sessions once max are connected. A max of 0
+// means no limit.
+func LimitSessions(max int) wish.Middleware {
+	var active atomic.Int64
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if max > 0 && active.Add(1) > int64(max) {
+				active.Add(-1)
+				wish.Fatalln(s, "The arena is full, try again later.")
+				return
+			}
+			if max > 0 {
+				defer active.Add(-1)
+			}
+			next(s)
+		}
+	}
+}
+
+func Fingerprint(s ssh.Session) string {
+	key := s.PublicKey()
+	if key == nil {
+		return ""
+	}
+	return gossh.FingerprintSHA256(key)
+}
+
+const badNameMessage = "Player names may not contain control characters, '|' or ','."
+
+// validName rejects names that would break the lines and fields of the score
+// and account files.
+func validName(name string) bool {
+	return !strings.ContainsAny(name, "|,") && !strings.ContainsFunc(name, unicode.IsControl)
+}
+
+// validFingerprint accepts only fingerprints in the form whoami prints them.
+func validFingerprint(fp string) bool {
+	hash, ok := strings.CutPrefix(fp, "SHA256:")
+	if !ok {
+		return false
+	}
+	sum, err := base64.RawStdEncoding.DecodeString(hash)
+	return err == nil && len(sum) == 32
+}
@@ -0,0 +1,43 @@
+package ssh
+
+import "testing"
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"aaron", true},
+		{"Ünïcödé player", true},
+		{"", true},
+		{"zed\naaron", false},
+		{"zed\r", false},
+		{"tab\there", false},
+		{"a|b", false},
+		{"a,b", false},
+	}
+	for _, tt := range tests {
+		if got := validName(tt.name); got != tt.want {
+			t.Errorf("validName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidFingerprint(t *testing.T) {
+	tests := []struct {
+		fp   string
+		want bool
+	}{
+		{"SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s", true},
+		{"SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2", false},
+		{"MD5:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s", false},
+		{"SHA256:x\naaron|SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s", false},
+		{"SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s,x", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := validFingerprint(tt.fp); got != tt.want {
+			t.Errorf("validFingerprint(%q) = %v, want %v", tt.fp, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/ayehia0/deathmatch/internal/game"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	miniBoardWidth  = 30
+	miniBoardHeight = 12
+	maxLevelRows    = 6
+)
+
+func deathMessage(cause game.DeathCause) string {
+	switch cause {
+	case game.CauseRobot:
+		return "A robot caught you"
+	case game.CauseJunk:
+		return "You walked into radioactive junk"
+	case game.CauseObstacle:
+		return "You walked into an obstacle"
+	case game.CauseShrubs:
+		return "The shrubs wore you down"
+	case game.CauseSelfDestruct:
+		return "You are your own worst enemy!"
+	default:
+		return ""
+	}
+}
+
+func renderPostMortem(g *game.Game, width, height int) string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	causeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
+
+	stat := func(label string, value int) string {
+		return labelStyle.Render(label+": ") + valueStyle.Render(formatInt(value))
+	}
+
+	var left strings.Builder
+	left.WriteString(titleStyle.Render("POST-MORTEM") + "\n\n")
+	left.WriteString(causeStyle.Render(deathMessage(g.DeathCause)) + "\n\n")
+	left.WriteString(stat("Level", g.Level) + "  " + stat("Score", g.Score) + "  " + stat("Turns", g.Turns) + "\n")
+	left.WriteString(stat("Robots destroyed", g.Stats.RobotsDestroyed) + "  " + stat("Best kill chain", g.Stats.BestKillChain) + "  " + stat("Undos", g.UndosUsed) + "\n\n")
+
+	left.WriteString(labelStyle.Render("Level  Teleports  EMPs  Blasters  Pickups  Kills") + "\n")
+	levels := g.Stats.Levels
+	if len(levels) > maxLevelRows {
+		left.WriteString(labelStyle.Render("  ...") + "\n")
+		levels = levels[len(levels)-maxLevelRows:]
+	}
+	for _, l := range levels {
+		left.WriteString(valueStyle.Render(
+			padRight(formatInt(l.Level), 7)+
+				padRight(formatInt(l.Teleports), 11)+
+				padRight(formatInt(l.EMPs), 6)+
+				padRight(formatInt(l.Blasters), 10)+
+				padRight(formatInt(l.Pickups), 9)+
+				formatInt(l.RobotsDestroyed)) + "\n")
+	}
+
+	board := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Render(miniBoard(g))
+	right := labelStyle.Render("Final board") + "\n" + board
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, left.String(), "    ", right)
+	footer := labelStyle.Render("[r] Restart  [v] Watch Replay  [q] Back")
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, body+"\n\n"+footer)
+}
+
+// miniBoard draws the arena one character per block of cells, scaled down to
+// fit next to the stats. When a block holds several things the most important
+// one wins.
+func miniBoard(g *game.Game) string {
+	scale := max(1, (g.Width+miniBoardWidth-1)/miniBoardWidth, (g.Height+miniBoardHeight-1)/miniBoardHeight)
+	w := (g.Width + scale - 1) / scale
+	h := (g.Height + scale - 1) / scale
+
+	rank := make([][]int, h)
+	for y := range rank {
+		rank[y] = make([]int, w)
+	}
+
+	glyphs := []string{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("237")).Render("·"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("&"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("#"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("*"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("R"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true).Render("@"),
+	}
+	mark := func(p game.Position, r int) {
+		if p.X < 0 || p.X >= g.Width || p.Y < 0 || p.Y >= g.Height {
+			return
+		}
+		cell := &rank[p.Y/scale][p.X/scale]
+		*cell = max(*cell, r)
+	}
+
+	for _, e := range g.Entities {
+		switch e.Type {
+		case game.EntityShrub:
+			mark(e.Pos, 1)
+		case game.EntityObstacle:
+			mark(e.Pos, 2)
+		case game.EntityJunk:
+			mark(e.Pos, 3)
+		case game.EntityRobot:
+			mark(e.Pos, 4)
+		}
+	}
+	mark(g.Player, 5)
+
+	var b strings.Builder
+	for y, row := range rank {
+		for _, r := range row {
+			b.WriteString(glyphs[r])
+		}
+		if y < len(rank)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func padRight(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"github.com/ayehia0/deathmatch/internal/game"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ReplayViewer struct {
+	replay  game.Replay
+	game    *game.Game
+	step    int
+	playing bool
+}
+
+func NewReplayViewer(replay game.Replay) *ReplayViewer {
+	v := &ReplayViewer{replay: replay}
+	v.seek(0)
+	return v
+}
+
+// seek rebuilds the game from the seed and re-applies the first step actions.
+// The engine is deterministic, so this is how stepping back works.
+func (v *ReplayViewer) seek(step int) {
+	step = max(0, min(step, len(v.replay.Actions)))
+
+	v.game = v.replay.Start()
+	for _, action := range v.replay.Actions[:step] {
+		action.Apply(v.game)
+	}
+	v.step = step
+}
+
+func (v *ReplayViewer) Forward() {
+	if v.step >= len(v.replay.Actions) {
+		v.playing = false
+		return
+	}
+	v.replay.Actions[v.step].Apply(v.game)
+	v.step++
+}
+
+func (v *ReplayViewer) Back() {
+	v.playing = false
+	v.seek(v.step - 1)
+}
+
+func (v *ReplayViewer) Rewind() {
+	v.playing = false
+	v.seek(0)
+}
+
+func (v *ReplayViewer) TogglePlay() {
+	if v.step >= len(v.replay.Actions) {
+		v.seek(0)
+	}
+	v.playing = !v.playing
+}
+
+func (v *ReplayViewer) Update() {
+	if v.playing {
+		v.Forward()
+	}
+}
+
+func (v *ReplayViewer) Render() string {
+	mode := "PAUSED"
+	if v.playing {
+		mode = "PLAYING"
+	}
+
+	status := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("11")).
+		Padding(0, 1).
+		Render("REPLAY " + mode + " " + formatInt(v.step) + "/" + formatInt(len(v.replay.Actions)) +
+			"  space: play/pause | ←/→: step | g: rewind | q: exit")
+
+	// The controls take the row the aiming hint would use, so the replay
+	// fits the same screen the game did. The arena still shows the aim.
+	view, _ := gameScreen(v.game)
+	return view + "\n" + status
+}
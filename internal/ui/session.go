@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"sync"
+
+	"github.com/ayehia0/deathmatch/internal/game"
+)
+
+// Session follows the game a model is playing, so the server can save it
+// once the program has exited, however it exited.
+type Session struct {
+	mu     sync.Mutex
+	game   *game.Game
+	saves  game.SaveStore
+	player string
+	// record saves a run the player died in but could still take back,
+	// once they start another game or leave.
+	record func()
+}
+
+func (s *Session) play(g *game.Game) {
+	s.mu.Lock()
+	record := s.record
+	s.game, s.record = g, nil
+	s.mu.Unlock()
+
+	if record != nil {
+		record()
+	}
+}
+
+// hold puts off recording the current run until it is really over. A nil
+// record drops the one held, as when the player undoes their death.
+func (s *Session) hold(record func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record = record
+}
+
+// Save records a run held back for undo and stores the game in progress, if
+// there is one, for the player to resume on their next connection.
+func (s *Session) Save() error {
+	s.mu.Lock()
+	g, record := s.game, s.record
+	s.record = nil
+	s.mu.Unlock()
+
+	if record != nil {
+		record()
+	}
+
+	if s.saves == nil || g == nil || g.GameOver {
+		return nil
+	}
+	return s.saves.Save(s.player, g)
+}
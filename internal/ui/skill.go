@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/ayehia0/deathmatch/internal/game"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m Model) renderSkillSelect() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	itemStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("CHOOSE YOUR SKILL LEVEL"))
+	b.WriteString("\n\n")
+
+	for _, skill := range game.Skills {
+		d := m.difficulty(skill)
+		diagonals := "off"
+		if d.DiagonalMoves {
+			diagonals = "on"
+		}
+		line := "  " + skill.String()
+		style := itemStyle
+		if skill == m.skill {
+			line = "> " + skill.String()
+			style = selectedStyle
+		}
+		b.WriteString(style.Render(line) + "\n")
+		b.WriteString(descStyle.Render("    "+skill.Description()) + "\n")
+		b.WriteString(descStyle.Render("    Robots: "+formatInt(d.RobotCount)+" (+"+formatInt(d.RobotGrowth)+"/level)"+
+			"  Tools: "+formatInt(d.Teleports)+"T "+formatInt(d.SafeTeleports)+"S "+formatInt(d.EMPs)+"E "+formatInt(d.Blasters)+"B"+
+			"  Diagonals: "+diagonals) + "\n")
+		b.WriteString(descStyle.Render("    Blaster: "+weaponSummary(d.Weapon())) + "\n\n")
+	}
+
+	mode := "Endless"
+	if m.campaign {
+		mode = "Campaign (" + formatInt(len(game.CampaignLevels())) + " levels, then endless)"
+	}
+	// Four skills of four lines each already fill most of an 80x24
+	// terminal, so the footer follows the mode line without a gap.
+	b.WriteString(selectedStyle.Render("Mode: "+mode) + "\n")
+	b.WriteString(descStyle.Render("↑↓/jk: choose | c: mode | enter: start | q: back"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, b.String())
+}
+
+func weaponSummary(w game.Weapon) string {
+	summary := w.Name + " (" + w.Shape.String() + " " + formatInt(w.Size)
+	if w.Range > 0 {
+		summary += ", range " + formatInt(w.Range)
+	}
+	if w.LeavesJunk {
+		summary += ", leaves junk"
+	}
+	return summary + ")"
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"log"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,8 +25,11 @@ type state int
 const (
 	welcomeState state = iota
 	helpState
+	skillSelectState
 	gameState
 	gameOverState
+	postMortemState
+	replayState
 )
 
 type helpTab int
@@ -46,31 +51,146 @@ type Model struct {
 	activeTab      helpTab
 	finalScore     int
 	finalLevel     int
-	selfDestruct   bool
+	deathCause     game.DeathCause
 	playerName     string
+	fingerprint    string
+	scores         game.ScoreStore
+	replaysDir     string
+	maxReplays     int
+	diagonalSkills []game.Skill
+	undoBudget     *int
+	skill          game.Skill
+	campaign       bool
+	replay         game.Replay
+	replayPath     string
+	replayViewer   *ReplayViewer
+	session        *Session
+	resume         *game.Game
 }
 
-func NewModel() Model {
-	return Model{
-		state:      welcomeState,
-		playerName: "Player",
-	}
+// Options carries the server-wide settings every session shares.
+type Options struct {
+	Scores       game.ScoreStore
+	ReplaysDir   string
+	DefaultSkill game.Skill
+	// DiagonalSkills lists the skill levels that allow diagonal moves. Nil
+	// keeps each preset's own setting.
+	DiagonalSkills []game.Skill
+	// UndoBudget overrides how many turns each level may be taken back.
+	// Nil keeps each preset's own budget.
+	UndoBudget *int
+	// Saves keeps games left unfinished when a session ends. Only players
+	// with a key can resume, since guests share a name.
+	Saves game.SaveStore
+	// MaxReplays is how many replays ReplaysDir keeps, 0 for all of them.
+	MaxReplays int
+}
+
+func NewModel(opts Options) Model {
+	return NewModelWithName("Player", "", opts)
 }
 
-func NewModelWithName(name string) Model {
+// NewModelWithName starts a session for the account name, whose scores are
+// recorded against the SSH key fingerprint that claimed it.
+func NewModelWithName(name, fingerprint string, opts Options) Model {
 	if name == "" {
 		name = "Player"
 	}
+
+	session := &Session{player: name}
+	var resume *game.Game
+	if opts.Saves != nil && fingerprint != "" {
+		session.saves = opts.Saves
+		g, err := opts.Saves.Load(name)
+		if err != nil {
+			log.Printf("loading saved game for %s: %v", name, err)
+		}
+		resume = g
+	}
+
 	return Model{
-		state:      welcomeState,
-		playerName: name,
+		state:          welcomeState,
+		playerName:     name,
+		fingerprint:    fingerprint,
+		scores:         opts.Scores,
+		replaysDir:     opts.ReplaysDir,
+		maxReplays:     opts.MaxReplays,
+		diagonalSkills: opts.DiagonalSkills,
+		undoBudget:     opts.UndoBudget,
+		skill:          opts.DefaultSkill,
+		session:        session,
+		resume:         resume,
 	}
 }
 
+// Session reports the game this model is playing, for saving it when the
+// connection ends.
+func (m Model) Session() *Session {
+	return m.session
+}
+
 func (m Model) Init() tea.Cmd {
 	return tick()
 }
 
+func (m Model) newGame() *game.Game {
+	return game.New((m.width-4)/2, m.height-5, m.difficulty(m.skill))
+}
+
+// play starts g and lets the session know about it.
+func (m *Model) play(g *game.Game) {
+	m.game = g
+	m.state = gameState
+	m.session.play(g)
+}
+
+func (m Model) newWelcomeScreen() *WelcomeScreen {
+	resumeLevel, resumeSize := 0, ""
+	if m.resume != nil {
+		resumeLevel = m.resume.Level
+		if !m.resumeFits() {
+			resumeSize = formatInt(screenWidth(m.resume)) + "x" + formatInt(screenHeight(m.resume))
+		}
+	}
+	return NewWelcomeScreen(m.width, m.height, m.playerName, m.topScores(), resumeLevel, resumeSize)
+}
+
+// resumeFits reports whether the saved game, which keeps the arena size it
+// was started with, can be drawn on the terminal as it is now.
+func (m Model) resumeFits() bool {
+	return screenWidth(m.resume) <= m.width && screenHeight(m.resume) <= m.height
+}
+
+// screenWidth and screenHeight are the terminal size a game's largest arena
+// needs: the size it started at, or a bigger campaign level it is on.
+func screenWidth(g *game.Game) int {
+	return max(g.Width, g.ArenaWidth)*2 + 4
+}
+
+func screenHeight(g *game.Game) int {
+	return max(g.Height, g.ArenaHeight) + 5
+}
+
+func (m Model) difficulty(skill game.Skill) game.Difficulty {
+	d := skill.Difficulty()
+	if m.diagonalSkills != nil {
+		d.DiagonalMoves = slices.Contains(m.diagonalSkills, skill)
+	}
+	if m.undoBudget != nil {
+		d.UndoBudget = *m.undoBudget
+	}
+	d.Campaign = m.campaign
+	return d
+}
+
+func (m Model) topScores() []game.ScoreEntry {
+	scores, err := game.TopScores(m.scores, 3)
+	if err != nil {
+		log.Printf("loading scores: %v", err)
+	}
+	return scores
+}
+
 func tick() tea.Cmd {
 	return tea.Tick(time.Millisecond*200, func(t time.Time) tea.Msg {
 		return tickMsg(t)
@@ -84,14 +204,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 		// Always recreate welcome screen on resize to keep it centered
-		m.welcomeScreen = NewWelcomeScreen(msg.Width, msg.Height)
+		m.welcomeScreen = m.newWelcomeScreen()
 
 		if m.game == nil {
-			m.game = game.New((msg.Width-4)/2, msg.Height-5, game.Difficulty{
-				RobotCount:    10,
-				ObstacleCount: 15,
-				MinSpawnDist:  5,
-			})
+			m.game = m.newGame()
 		}
 		m.viewport = viewport.New(msg.Width, msg.Height-4)
 		m.viewport.SetContent(m.getHelpContent())
@@ -103,17 +219,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.state == gameOverState && m.gameOverScreen != nil {
 			m.gameOverScreen.Update()
 		}
+		if m.state == replayState && m.replayViewer != nil {
+			m.replayViewer.Update()
+		}
 		if m.state == gameState && m.game != nil && m.game.GameOver {
 			m.state = gameOverState
 			m.finalScore = m.game.Score
 			m.finalLevel = m.game.Level
-			m.selfDestruct = m.game.SelfDestruct
+			m.deathCause = m.game.DeathCause
 
-			game.SaveScore(m.playerName, m.finalLevel, m.finalScore)
+			m.replay = m.game.Replay()
+			m.replayPath = ""
+			message := deathMessage(m.deathCause)
+			if m.game.CanUndo() {
+				// The player may still take the death back, so the run is
+				// recorded once they start another game or leave instead.
+				run := m
+				m.session.hold(func() {
+					if _, err := run.recordRun(); err != nil {
+						log.Printf("saving score for %s: %v", run.playerName, err)
+					}
+				})
+			} else {
+				path, err := m.recordRun()
+				m.replayPath = path
+				if err != nil {
+					log.Printf("saving score for %s: %v", m.playerName, err)
+					message = "Your score could not be saved!"
+				}
+			}
 
-			message := ""
-			if m.selfDestruct {
-				message = "You are your own worst enemy!"
+			prompt := "[d] Post-Mortem  [r] Restart  [v] Watch Replay  [q] Quit"
+			if m.game.CanUndo() {
+				prompt = "[z] Undo  " + prompt
 			}
 
 			colors := []lipgloss.Color{"9", "196", "160", "124"}
@@ -122,13 +260,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.height,
 				"GAME OVER",
 				message,
-				"Level: "+formatInt(m.finalLevel)+"  Score: "+formatInt(m.finalScore),
-				"[r] Restart  [q] Quit",
+				"Level: "+formatInt(m.finalLevel)+"  Score: "+formatInt(m.finalScore)+"  Skill: "+m.game.Difficulty.Skill.String(),
+				prompt,
 				colors,
 			)
 		}
 		return m, tick()
 	case tea.KeyMsg:
+		if m.state == welcomeState && m.resume != nil {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "y":
+				if !m.resumeFits() {
+					return m, nil
+				}
+				m.play(m.resume)
+			case "n":
+			default:
+				return m, nil
+			}
+			if err := m.session.saves.Delete(m.playerName); err != nil {
+				log.Printf("deleting saved game for %s: %v", m.playerName, err)
+			}
+			m.resume = nil
+			m.welcomeScreen = m.newWelcomeScreen()
+			return m, nil
+		}
+
 		if m.state == welcomeState {
 			switch msg.String() {
 			case "h":
@@ -147,17 +306,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.viewport.SetContent(m.getHelpContent())
 				return m, nil
 			default:
-				// Recreate game with current window size when starting
-				m.game = game.New((m.width-4)/2, m.height-5, game.Difficulty{
-					RobotCount:    10,
-					ObstacleCount: 15,
-					MinSpawnDist:  5,
-				})
-				m.state = gameState
+				m.state = skillSelectState
 				return m, nil
 			}
 		}
 
+		if m.state == skillSelectState {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "q", "esc":
+				m.state = welcomeState
+			case "up", "k":
+				if m.skill > game.Skills[0] {
+					m.skill--
+				}
+			case "down", "j":
+				if m.skill < game.Skills[len(game.Skills)-1] {
+					m.skill++
+				}
+			case "c":
+				m.campaign = !m.campaign
+			case "enter", " ":
+				// Recreate game with current window size when starting
+				m.play(m.newGame())
+			}
+			return m, nil
+		}
+
 		if m.state == helpState {
 			switch msg.String() {
 			case "q":
@@ -180,42 +356,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "q", "ctrl+c":
 				return m, tea.Quit
 			case "esc":
-				if m.game.BlasterActive {
-					m.game.BlasterActive = false
-				}
+				m.game.CancelTargeting()
 			case "t":
-				if !m.game.BlasterActive {
-					m.game.Teleport()
-				}
+				m.game.Teleport()
+			case "s":
+				m.game.SafeTeleport()
+			case "x":
+				m.game.ToggleBlink()
 			case "e":
-				if !m.game.BlasterActive {
-					m.game.UseEMP()
-				}
-			case "b":
+				m.game.UseEMP()
+			case "p":
+				m.game.UseEMPPulse()
+			case "v":
+				m.game.ToggleWave()
+			case "f":
 				m.game.ToggleBlaster()
-			case "up", "k":
-				if m.game.BlasterActive {
-					m.game.MoveBlasterTarget(0, -1)
-				} else {
-					m.game.MovePlayer(0, -1)
-				}
-			case "down", "j":
-				if m.game.BlasterActive {
-					m.game.MoveBlasterTarget(0, 1)
-				} else {
-					m.game.MovePlayer(0, 1)
+			case ".", " ", "5":
+				if !m.game.Targeting() {
+					m.game.Wait()
 				}
-			case "left", "h":
-				if m.game.BlasterActive {
-					m.game.MoveBlasterTarget(-1, 0)
-				} else {
-					m.game.MovePlayer(-1, 0)
+			case "w":
+				if !m.game.Targeting() {
+					m.game.LastStand()
 				}
-			case "right", "l":
-				if m.game.BlasterActive {
-					m.game.MoveBlasterTarget(1, 0)
-				} else {
-					m.game.MovePlayer(1, 0)
+			case "z", "ctrl+z":
+				m.game.Undo()
+			default:
+				if d, ok := directions[msg.String()]; ok {
+					m.step(d)
 				}
 			}
 		}
@@ -225,18 +393,112 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "q", "ctrl+c":
 				return m, tea.Quit
 			case "r":
-				m.game = game.New((m.width-4)/2, m.height-5, game.Difficulty{
-					RobotCount:    10,
-					ObstacleCount: 15,
-					MinSpawnDist:  5,
-				})
-				m.state = gameState
+				m.play(m.newGame())
+			case "z", "ctrl+z":
+				if m.game.Undo() {
+					m.session.hold(nil)
+					m.state = gameState
+				}
+			case "d":
+				m.state = postMortemState
+			case "v":
+				m.openReplay()
+			}
+			return m, nil
+		}
+
+		if m.state == postMortemState {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "q", "esc":
+				m.state = gameOverState
+			case "r":
+				m.play(m.newGame())
+			case "v":
+				m.openReplay()
+			}
+			return m, nil
+		}
+
+		if m.state == replayState {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "q", "esc":
+				m.state = gameOverState
+			case " ":
+				m.replayViewer.TogglePlay()
+			case "right", "l":
+				m.replayViewer.Forward()
+			case "left", "h":
+				m.replayViewer.Back()
+			case "g", "home":
+				m.replayViewer.Rewind()
 			}
 		}
 	}
 	return m, nil
 }
 
+// directions maps arrows, vi keys (hjkl plus yubn for diagonals) and the
+// numeric keypad onto movement deltas.
+var directions = map[string]game.Position{
+	"up": {X: 0, Y: -1}, "k": {X: 0, Y: -1}, "8": {X: 0, Y: -1},
+	"down": {X: 0, Y: 1}, "j": {X: 0, Y: 1}, "2": {X: 0, Y: 1},
+	"left": {X: -1, Y: 0}, "h": {X: -1, Y: 0}, "4": {X: -1, Y: 0},
+	"right": {X: 1, Y: 0}, "l": {X: 1, Y: 0}, "6": {X: 1, Y: 0},
+	"y": {X: -1, Y: -1}, "7": {X: -1, Y: -1},
+	"u": {X: 1, Y: -1}, "9": {X: 1, Y: -1},
+	"b": {X: -1, Y: 1}, "1": {X: -1, Y: 1},
+	"n": {X: 1, Y: 1}, "3": {X: 1, Y: 1},
+}
+
+func (m Model) step(d game.Position) {
+	if d.X != 0 && d.Y != 0 && !m.game.Difficulty.DiagonalMoves {
+		return
+	}
+	switch {
+	case m.game.BlasterActive:
+		m.game.MoveBlasterTarget(d.X, d.Y)
+	case m.game.BlinkActive:
+		m.game.MoveBlinkTarget(d.X, d.Y)
+	case m.game.WaveActive:
+		m.game.AimWave(d.X, d.Y)
+	default:
+		m.game.MovePlayer(d.X, d.Y)
+	}
+}
+
+// recordRun saves the score and replay of the run that has just ended and
+// returns the replay's path. A replay that cannot be saved is only logged.
+func (m Model) recordRun() (string, error) {
+	path, err := game.SaveReplay(m.replaysDir, m.playerName, m.replay, m.maxReplays)
+	if err != nil {
+		log.Printf("saving replay for %s: %v", m.playerName, err)
+	}
+
+	return path, m.scores.Save(game.ScoreEntry{
+		Name:        m.playerName,
+		Level:       m.finalLevel,
+		Score:       m.finalScore,
+		Skill:       m.game.Difficulty.Skill,
+		Fingerprint: m.fingerprint,
+		Undos:       m.game.UndosUsed,
+	})
+}
+
+func (m *Model) openReplay() {
+	replay := m.replay
+	if m.replayPath != "" {
+		if loaded, err := game.LoadReplay(m.replayPath); err == nil {
+			replay = loaded
+		}
+	}
+	m.replayViewer = NewReplayViewer(replay)
+	m.state = replayState
+}
+
 func (m Model) View() string {
 	if m.width < minWidth || m.height < minHeight {
 		msg := lipgloss.NewStyle().
@@ -255,12 +517,21 @@ func (m Model) View() string {
 	if m.state == helpState {
 		return m.renderHelp()
 	}
+	if m.state == skillSelectState {
+		return m.renderSkillSelect()
+	}
 	if m.state == gameOverState {
 		if m.gameOverScreen != nil {
 			return m.gameOverScreen.Render()
 		}
 		return ""
 	}
+	if m.state == postMortemState {
+		return renderPostMortem(m.game, m.width, m.height)
+	}
+	if m.state == replayState && m.replayViewer != nil {
+		return m.replayViewer.Render()
+	}
 	return gameView(m.game)
 }
 
@@ -278,36 +549,109 @@ and survive as long as possible.
 - Trapped in a walled arena with hostile robots
 - You are unarmed; survival depends on movement, positioning, and strategy
 - Robots move diagonally toward you after each of your moves
+- On most skill levels you can move diagonally too
+
+## Robot Kinds
+Early levels only have plain robots. Later levels mix in smarter and faster ones:
+- **RR Greedy**: charges straight at you and stops dead at obstacles
+- **PP Pathfinder**: finds the shortest way around obstacles and junk
+- **FF Flanker**: heads for where you are going, not where you are
+- **CC Cautious**: chases like a greedy robot but never walks into junk
+- **XX Fast**: takes two steps every turn, colliding after each one, and is
+  worth **25 points**
 
 ## Robot Collisions & Junk
 - Robots chase you relentlessly
+- All robots move at the same time
 - When 2+ robots collide, they create **radioactive junk** (yellow **)
-- Robots hitting junk self-destruct
+- Two robots trying to swap places crash into each other
+- Robots hitting junk self-destruct, even junk made that same turn
 - Junk and obstacles are **deadly to humans**
 
+## Standing Still
+- **Wait (. or space)**: stay where you are for one turn and let the robots come
+- **Last stand (w)**: keep waiting until the level is cleared, you die, or the
+  robots stop moving. If you survive, every robot destroyed while you stood
+  earns a **+10 bonus**
+
 ## Obstacles
 - Gray obstacles (##) block movement for both you and robots
 - Use them strategically to funnel robots together
 - Robots cannot pass through obstacles
 
-## Defensive Tools (Reset each level)
-- **Teleporter (t)**: 5 uses, teleports you to a random safe location (-2 points)
-- **EMP (e)**: 3 uses, disables all robots for 5 turns
-- **Blaster (b)**: 2 uses, destroys all robots in a 3x3 grid
-  - Enter targeting mode, move the grid, press 'b' to fire or 'esc' to cancel
+## Shrubs
+- Green shrubs (&&) block movement but not vision
+- Robots walking into a shrub either **crush** it and take its place,
+  or **explode** with it and leave junk behind
+- Running into a shrub costs **1 health** and **5 points**, and the robots still move
+- You have 3 health, restored each level; losing it all ends the game
+
+## Pickups
+Every so often a pickup drops onto a free cell. Step onto it to collect it
+before it fades away or a robot tramples it:
+- **+T / +E / +B**: an extra teleport, EMP or blaster charge
+- **()**: a shield for 10 turns that wrecks the first robots to reach you, or that you walk into
+- **x2**: doubles the points for kills for 10 turns
+
+## Undo
+- **Undo (z)**: take back your last turn, even the one that killed you
+- Each skill level allows a few undos per level (Novice 5, Standard 2,
+  Veteran 1, Nightmare none), and each one costs **15 points**
+- Scores reached with undo are marked on the leaderboard
+
+## Defensive Tools (Refilled each level)
+- **Teleporter (t)**: teleports you to a random free cell, which may be
+  right next to a robot (-2 points)
+- **Blink (x)**: spends a teleport to jump to a free cell you pick within
+  3 cells (-2 points)
+- **Safe teleport (s)**: a scarcer charge that lands you where no robot can
+  reach you next turn (-2 points)
+- **EMP**: stuns robots, which sit out their turns in place
+  - **e**: every robot on the board for 5 turns
+  - **p** pulse: robots within 3 cells of you for 8 turns
+  - **v** wave: robots in a cone you aim, up to 8 cells out, for 8 turns
+- **Blaster (f)**: destroys every robot its blast covers. Each skill level
+  hands out its own blaster:
+  - **Shock ring** (Novice): the ring of cells 2 away from where you aim,
+    anywhere in the arena, sparing its centre
+  - **Blaster** (Standard): a 3x3 square anywhere in the arena
+  - **Cross blaster** (Veteran): a cross with 2-cell arms, aimed up to 8
+    cells away
+  - **Rail gun** (Nightmare): a 7-cell line along the way you aim it, up to
+    6 cells away, leaving the robots it hits as junk
+  - Enter targeting mode, move the grid, press 'f' to fire or 'esc' to cancel
   - WARNING: You die if you're in the blast zone!
 
 ## Scoring System
-- **+10 points** per robot destroyed
+- **+10 points** per robot destroyed, **+25** for a fast robot
 - **Consecutive kill multiplier**: Every 5 kills adds +1x multiplier
 - **+50 points** for completing a level
-- **-2 points** for using teleporter
+- **-2 points** for using a teleport, blink or safe teleport
+- **-5 points** for running into a shrub
+- **-15 points** for each undo
+
+## Skill Levels
+Pick a skill level before each game:
+- **Novice**: fewer robots, more tools, shrubs that robots readily explode on
+- **Standard**: the classic arena (5 teleports, 2 safe teleports, 3 EMPs,
+  2 blasters)
+- **Veteran**: more robots that spawn closer, fewer tools
+- **Nightmare**: robots everywhere and almost nothing to help you
+
+Skill controls starting robots, how fast each level grows, spawn distance,
+shrub density, how many tools you get back each level and how soon
+smarter robots join the hunt.
+
+## Campaign
+Press **c** on the skill screen to switch to campaign mode. The campaign
+plays through hand-built arenas in order, each with its own layout and
+tools, then carries on with endless random levels.
 
 ## Endless Progression
 - Clear all robots to advance to the next level
 - Each level increases difficulty:
-  - More robots spawn
-- Tools are replenished each level (+5 teleports, +3 EMPs, +1 blaster)
+  - More robots, obstacles and shrubs spawn
+- Tools are replenished each level
 - High score is the only goal—there is no escape!
 
 > There is no escape, no final level, and no winning—only a score to beat.`
@@ -316,24 +660,61 @@ and survive as long as possible.
 		content = `# CONTROLS
 
 ## Movement
-Use **arrow keys** or **hjkl** (vim keys) to move:
-- **↑ / k** - Move up
-- **↓ / j** - Move down
-- **← / h** - Move left
-- **→ / l** - Move right
+Use **arrow keys**, **hjkl** (vim keys) or the **numeric keypad** to move.
+Diagonals use **yubn** or the keypad corners when your skill level allows them.
+
+` + "```" + `
+  y   k   u        7   8   9
+    ↖ ↑ ↗            ↖ ↑ ↗
+  h ←  ·  → l      4 ←  5  → 6
+    ↙ ↓ ↘            ↙ ↓ ↘
+  b   j   n        1   2   3
+` + "```" + `
+
+The keypad **5** waits a turn.
+
+## Waiting
+- **. / space / 5** - Wait one turn
+- **w** - Last stand: wait until the level clears or the robots stop moving
+
+## Undo
+- **z** - Take back your last turn (also from the game over screen)
 
 ## Tools
-- **t** - Use teleporter (5 per level, -2 points)
-- **e** - Use EMP (3 per level, disables robots for 5 turns)
-- **b** - Use blaster (2 per level)
+- **t** - Use teleporter (-2 points)
+- **s** - Use safe teleport (-2 points)
+- **x** - Blink
+  - First press: show the landing zone and a cursor on you
+  - Move the cursor up to 3 cells away
+  - Press **x** again to jump, **esc** to cancel
+- **e** - EMP: stun every robot for 5 turns
+- **p** - EMP pulse: stun robots within 3 cells for 8 turns
+- **v** - EMP wave
+  - First press: show the cone the wave will sweep
+  - Aim it with any movement key
+  - Press **v** again to fire, **esc** to cancel
+- **f** - Use blaster
   - First press: Enter targeting mode
-  - Move with arrow keys/hjkl to position 3x3 grid
-  - Press **b** again to fire
+  - Move with any movement key to position the blast; blasters with a
+    limited range stop at their reach
+  - The status line shows the kills and points the shot would earn, and
+    turns red with a warning when you are inside the blast
+  - Press **f** again to fire
   - Press **esc** to cancel
 
 ## Game Controls
-- **q** - Quit game
+- **q** - Quit game; an unfinished run is saved and offered back the next
+  time you connect with the same key
 - **r** - Restart (when game over)
+- **d** - Post-mortem: cause of death, stats and final board (when game over)
+- **v** - Watch a replay of your run (when game over)
+
+## Replay Viewer
+- **Space** - Play / pause
+- **→ / l** - Step forward
+- **← / h** - Step back
+- **g** - Rewind to the start
+- **q** - Back to the game over screen
 
 ## Help Navigation
 - **Tab** - Switch between help tabs
@@ -343,22 +724,35 @@ Use **arrow keys** or **hjkl** (vim keys) to move:
 ## Legend
 - **@@** - You (green)
 - **RR** - Robot (red)
+- **PP** / **FF** / **CC** - Pathfinder, flanker and cautious robots
+- **XX** - Fast robot (bright red)
+- **R5** - Stunned robot and the turns it has left (blue)
 - **##** - Obstacle (gray)
 - **\*\*** - Radioactive junk (yellow)
-- **░░** - Blaster target zone (gray)`
+- **&&** - Shrub (green)
+- **+T** / **+E** / **+B** / **()** / **x2** - Pickups (cyan, dim when about to vanish)
+- **░░** - Blaster target zone (gray, red when you are inside it)
+- **░░** - EMP wave cone (blue)
+- **░░** - Blink landing zone: green is safe, orange is within a robot's
+  reach next turn, red under the cursor means the cell is taken`
 
 	case scoringTab:
 		content = `# SCORING
 
 ## Points Earned
 - **Destroy robot**: +10 points (base)
+- **Destroy fast robot**: +25 points (base)
+- **x2 pickup**: doubles kill points for 10 turns
 - **Complete level**: +50 points
+- **Last stand bonus**: +10 points per robot destroyed during a last stand you survive
 - **Consecutive kill multiplier**: Every 5 consecutive kills adds +1x multiplier
   - Example: 5 kills = 1x, 10 kills = 2x, 15 kills = 3x, etc.
   - Multiplier applies to all robot kills
 
 ## Penalties
-- **Use teleporter**: -2 points per use
+- **Use teleporter, blink or safe teleport**: -2 points per use
+- **Run into a shrub**: -5 points and 1 health per bump
+- **Undo a turn**: -15 points per undo
 
 ## How Multiplier Works
 When you destroy robots without dying, your consecutive kill count increases.
@@ -366,12 +760,18 @@ The multiplier makes each kill worth more:
 - First 5 kills: 10 points each (1x multiplier)
 - Next 5 kills: 20 points each (2x multiplier)
 - Next 5 kills: 30 points each (3x multiplier)
-- And so on...
+- And so on... Fast robots are worth 25 points times the multiplier.
 
 ## Leaderboard
-- Your **best score** per username is saved
+- Your **best score** per name is saved, along with its skill level
+- Names belong to the SSH key that first played with them; other keys
+  asking for a taken name play under a suffixed one (e.g. alice-2)
+- Link another key to your name with
+  ` + "`ssh <name>@host link <fingerprint>`" + ` from a key you already use,
+  and find a key's fingerprint with ` + "`ssh -i <key> <name>@host whoami`" + `
 - Top 3 scores shown on welcome screen
-- Format: Name, Level reached, Total score
+- Format: Name, Level reached, Total score, Skill
+- Scores reached with undo are marked **(undo)**
 - Beat your own record or compete with others!
 
 ## Strategy Tips
@@ -410,6 +810,16 @@ func (m Model) renderHelp() string {
 }
 
 func gameView(g *game.Game) string {
+	view, hint := gameScreen(g)
+	if hint != "" {
+		view += "\n" + hint
+	}
+	return view
+}
+
+// gameScreen renders the arena and status bars, and separately the aiming
+// hint that goes on the spare row under them.
+func gameScreen(g *game.Game) (view, hint string) {
 	grid := make([][]string, g.Height)
 	blasterGrid := make([][]bool, g.Height)
 	for i := range grid {
@@ -420,14 +830,16 @@ func gameView(g *game.Game) string {
 		}
 	}
 
+	var blast game.BlastPreview
+	zoneColor := lipgloss.Color("240")
 	if g.BlasterActive {
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				y := g.BlasterTarget.Y + dy
-				x := g.BlasterTarget.X + dx
-				if y >= 0 && y < g.Height && x >= 0 && x < g.Width {
-					blasterGrid[y][x] = true
-				}
+		blast = g.SimulateBlast()
+		if blast.HitsPlayer {
+			zoneColor = lipgloss.Color("9")
+		}
+		for y := range blasterGrid {
+			for x := range blasterGrid[y] {
+				blasterGrid[y][x] = g.InBlastZone(game.Position{X: x, Y: y})
 			}
 		}
 	}
@@ -445,13 +857,25 @@ func gameView(g *game.Game) string {
 	var arena strings.Builder
 	for y, row := range grid {
 		for x, cell := range row {
-			if blasterGrid[y][x] {
+			p := game.Position{X: x, Y: y}
+			switch {
+			case blasterGrid[y][x]:
 				if cell == "  " {
-					arena.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("░░"))
+					arena.WriteString(lipgloss.NewStyle().Foreground(zoneColor).Render("░░"))
 				} else {
-					arena.WriteString(lipgloss.NewStyle().Background(lipgloss.Color("240")).Render(cell))
+					arena.WriteString(lipgloss.NewStyle().Background(zoneColor).Render(cell))
 				}
-			} else {
+			case g.BlinkActive && p == g.BlinkTarget:
+				arena.WriteString(lipgloss.NewStyle().Background(blinkColor(g, p)).Render(cell))
+			case g.BlinkActive && cell == "  " && g.InBlinkRange(p):
+				arena.WriteString(lipgloss.NewStyle().Foreground(blinkColor(g, p)).Render("░░"))
+			case g.WaveActive && g.InWave(p):
+				if cell == "  " {
+					arena.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render("░░"))
+				} else {
+					arena.WriteString(lipgloss.NewStyle().Background(lipgloss.Color("24")).Render(cell))
+				}
+			default:
 				arena.WriteString(cell)
 			}
 		}
@@ -470,29 +894,105 @@ func gameView(g *game.Game) string {
 		Padding(0, 1)
 
 	empStatus := ""
-	if g.EMPTurnsLeft > 0 {
+	if stunned := stunnedRobots(g); stunned > 0 {
 		empStatus = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("11")).
-			Render(" (ACTIVE: " + formatInt(g.EMPTurnsLeft) + " turns)")
+			Render(" (" + formatInt(stunned) + " stunned)")
 	}
 
-	blasterStatus := ""
-	if g.BlasterActive {
-		blasterStatus = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
-			Render(" [TARGETING MODE - Press 'b' to fire, 'esc' to cancel]")
+	undoStatus := ""
+	if g.Difficulty.UndoBudget > 0 {
+		undoStatus = "  [z] Undos: " + formatInt(g.UndosLeft)
+	}
+
+	boostStatus := ""
+	if g.ShieldTurns > 0 {
+		boostStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("51")).Render("  Shield: " + formatInt(g.ShieldTurns))
+	}
+	if g.BoostTurns > 0 {
+		boostStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("51")).Render("  x2: " + formatInt(g.BoostTurns))
 	}
 
 	status := statusStyle.Render(
-		"Level: " + formatInt(g.Level) +
+		"Level: " + formatInt(g.Level) + levelName(g) +
 			"  Score: " + formatInt(g.Score) +
-			"  [t] Teleports: " + formatInt(g.Teleports) +
-			"  [e] EMPs: " + formatInt(g.EMPs) + empStatus +
-			"  [b] Blasters: " + formatInt(g.Blasters) + blasterStatus +
+			"  HP: " + formatInt(g.Health) + "/" + formatInt(g.MaxHealth()) +
+			undoStatus + boostStatus +
 			"  [q] Quit",
 	)
+	tools := statusStyle.Render(
+		"[t/x] Teleports: " + formatInt(g.Teleports) +
+			"  [s] Safe: " + formatInt(g.SafeTeleports) +
+			"  [e/p/v] EMPs: " + formatInt(g.EMPs) + empStatus +
+			"  [f] " + g.Weapon.Name + ": " + formatInt(g.Blasters),
+	)
+
+	// Aiming hints get a line of their own so an 80-column terminal shows
+	// all of them. The arena leaves a spare row for it, and only one tool
+	// is aimed at a time.
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	switch {
+	case g.BlasterActive:
+		hint = renderBlastPreview(blast)
+	case g.BlinkActive:
+		hint = hintStyle.Render("[BLINK - aim with the movement keys, x: jump | esc: cancel]")
+	case g.WaveActive:
+		hint = hintStyle.Render("[WAVE - aim with the movement keys, v: fire | esc: cancel]")
+	}
+
+	if hint != "" {
+		hint = statusStyle.Render(hint)
+	}
+	return boxStyle.Render(arena.String()) + "\n" + status + "\n" + tools, hint
+}
+
+func levelName(g *game.Game) string {
+	if g.LevelName == "" {
+		return ""
+	}
+	return " (" + g.LevelName + ")"
+}
+
+// renderBlastPreview sums up what firing now would do, and shouts when the
+// player is standing in the blast.
+func renderBlastPreview(p game.BlastPreview) string {
+	if p.HitsPlayer {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("9")).
+			Bold(true).
+			Render("!! YOU ARE IN THE BLAST ZONE - 'f' KILLS YOU, 'esc' to cancel !!")
+	}
 
-	return boxStyle.Render(arena.String()) + "\n" + status
+	preview := formatInt(p.Kills) + " kills"
+	if p.Kills == 1 {
+		preview = "1 kill"
+	}
+	if p.Kills > 0 {
+		preview += " +" + formatInt(p.Points)
+		if p.Multiplier > 1 {
+			preview += " (" + formatInt(p.Multiplier) + "x)"
+		}
+	}
+	if p.ClearsLevel {
+		preview += ", clears the level"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("11")).
+		Render("[TARGETING: " + preview + " - f: fire | esc: cancel]")
+}
+
+// blinkColor shades a blink landing: green when safe, orange when a robot
+// could reach it next turn and red when the cell is taken.
+func blinkColor(g *game.Game, p game.Position) lipgloss.Color {
+	switch {
+	case !g.CanBlinkTo(p):
+		return lipgloss.Color("9")
+	case !g.SafeLanding(p):
+		return lipgloss.Color("214")
+	default:
+		return lipgloss.Color("42")
+	}
 }
 
 func formatInt(n int) string {
@@ -510,16 +1010,84 @@ func formatInt(n int) string {
 	return result
 }
 
+func renderRobot(kind game.RobotKind) string {
+	switch kind {
+	case game.RobotPathfinder:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Render("PP")
+	case game.RobotFlanker:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render("FF")
+	case game.RobotCautious:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("167")).Render("CC")
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("RR")
+	}
+}
+
+func stunnedRobots(g *game.Game) int {
+	n := 0
+	for _, e := range g.Entities {
+		if e.Type == game.EntityRobot && e.Stunned > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// renderStunned shows a stunned robot by its letter and the turns it has
+// left to sit out.
+func renderStunned(e game.Entity) string {
+	letter := "R"
+	switch {
+	case e.Fast:
+		letter = "X"
+	case e.Kind == game.RobotPathfinder:
+		letter = "P"
+	case e.Kind == game.RobotFlanker:
+		letter = "F"
+	case e.Kind == game.RobotCautious:
+		letter = "C"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(letter + formatInt(min(e.Stunned, 9)))
+}
+
+// renderPickup draws a pickup, fading it out over its last few turns.
+func renderPickup(e game.Entity) string {
+	glyph := "+T"
+	switch e.Pickup {
+	case game.PickupEMP:
+		glyph = "+E"
+	case game.PickupBlaster:
+		glyph = "+B"
+	case game.PickupShield:
+		glyph = "()"
+	case game.PickupMultiplier:
+		glyph = "x2"
+	}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("51")).Bold(true)
+	if e.TTL <= 3 {
+		style = lipgloss.NewStyle().Foreground(lipgloss.Color("30"))
+	}
+	return style.Render(glyph)
+}
+
 func renderEntity(e game.Entity) string {
 	switch e.Type {
 	case game.EntityRobot:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("RR")
+		if e.Stunned > 0 {
+			return renderStunned(e)
+		}
+		if e.Fast {
+			return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true).Render("XX")
+		}
+		return renderRobot(e.Kind)
 	case game.EntityObstacle:
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("##")
 	case game.EntityJunk:
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("**")
 	case game.EntityShrub:
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("&&")
+	case game.EntityPickup:
+		return renderPickup(e)
 	default:
 		return "  "
 	}
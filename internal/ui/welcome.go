@@ -10,17 +10,33 @@ type WelcomeScreen struct {
 	topScores []game.ScoreEntry
 }
 
-func NewWelcomeScreen(width, height int) *WelcomeScreen {
-	topScores := game.GetTopScores(3)
-
+// NewWelcomeScreen greets the player. A resumeLevel above zero offers to
+// resume the game they left on that level instead of the usual menu, or,
+// when resumeSize is set, says how big a terminal that game needs.
+func NewWelcomeScreen(width, height int, playerName string, topScores []game.ScoreEntry, resumeLevel int, resumeSize string) *WelcomeScreen {
+	// The screen leaves out a subtitle wider than itself, so list only as
+	// many scores as fit on one line.
 	subtitle := ""
-	if len(topScores) > 0 {
-		subtitle = "TOP SCORES: "
-		for i, s := range topScores {
-			if i > 0 {
-				subtitle += " | "
-			}
-			subtitle += formatInt(i+1) + ". " + s.Name + " Lvl" + formatInt(s.Level) + " " + formatInt(s.Score) + "pts"
+	for i, s := range topScores {
+		entry := formatInt(i+1) + ". " + s.Name + " Lvl" + formatInt(s.Level) + " " + formatInt(s.Score) + "pts " + s.Skill.String()
+		if s.Undos > 0 {
+			entry += " (undo)"
+		}
+		line := subtitle + " | " + entry
+		if i == 0 {
+			line = "TOP SCORES: " + entry
+		}
+		if len(line) > width {
+			break
+		}
+		subtitle = line
+	}
+
+	prompt := "[enter] Play  [h] How to Play  [c] Controls  [s] Scoring"
+	if resumeLevel > 0 {
+		prompt = "Resume game from level " + formatInt(resumeLevel) + "? [y/n]"
+		if resumeSize != "" {
+			prompt = "Your level " + formatInt(resumeLevel) + " game needs a " + resumeSize + " terminal. [n] Discard it"
 		}
 	}
 
@@ -30,9 +46,9 @@ func NewWelcomeScreen(width, height int) *WelcomeScreen {
 			width,
 			height,
 			"ROBOT DEATHMATCH ARENA",
-			"",
+			"Playing as "+playerName,
 			subtitle,
-			"[h] How to Play  [c] Controls  [s] Scoring",
+			prompt,
 			colors,
 		),
 		topScores: topScores,